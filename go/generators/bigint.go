@@ -0,0 +1,105 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/big"
+	"math/rand"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// bigint
+
+type BigIntRandomGenerator struct {
+	Min      *big.Int
+	Max      *big.Int
+	EdgeBias float64
+}
+
+// Generates a random integer number of any size comprised between two bounds,
+// which is an edge value (min, max, 0, -1 or 1) with a probability given by the
+// edge bias.
+func (g BigIntRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	if g.EdgeBias > 0 && r.Float64() < g.EdgeBias {
+		edges := make([]*big.Int, 0, 5)
+		for _, value := range []*big.Int{g.Min, g.Max, big.NewInt(0), big.NewInt(-1), big.NewInt(1)} {
+			if g.Min.Cmp(value) <= 0 && value.Cmp(g.Max) <= 0 {
+				edges = append(edges, value)
+			}
+		}
+		return BigIntValue(edges[randint(r, 0, len(edges)-1)])
+	}
+	return BigIntValue(randbigint(r, g.Min, g.Max))
+}
+
+func (g BigIntRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// randbigint draws an integer comprised between two bounds, all the integers
+// being equally likely.
+func randbigint(r *rand.Rand, min *big.Int, max *big.Int) *big.Int {
+	n := new(big.Int).Sub(max, min)
+	n.Add(n, big.NewInt(1))
+	x := new(big.Int).Rand(r, n)
+	return x.Add(x, min)
+}
+
+// bigint(min,max)
+func newBigIntGenerator(p *Params) (RandomGenerator, error) {
+	min, max := new(big.Int), new(big.Int)
+	if err := p.Scan(min, max); err != nil {
+		return nil, err
+	}
+	if min.Cmp(max) > 0 {
+		return nil, p.Errorf("invalid bounds, %s is greater than %s", min, max)
+	}
+	bias, err := edgeBias(p)
+	if err != nil {
+		return nil, err
+	}
+	return BigIntRandomGenerator{min, max, bias}, nil
+}
+
+// digits(n)
+func newDigitsGenerator(p *Params) (RandomGenerator, error) {
+	var n int
+	if err := p.Scan(&n); err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, p.Arg(0).Errorf("number of digits must be positive")
+	}
+	bias, err := edgeBias(p)
+	if err != nil {
+		return nil, err
+	}
+
+	// Numbers with n digits are comprised between 10^(n-1) and 10^n-1, except
+	// for zero which has one digit.
+	ten := big.NewInt(10)
+	min := new(big.Int).Exp(ten, big.NewInt(int64(n-1)), nil)
+	if n == 1 {
+		min.SetInt64(0)
+	}
+	max := new(big.Int).Exp(ten, big.NewInt(int64(n)), nil)
+	max.Sub(max, big.NewInt(1))
+	return BigIntRandomGenerator{min, max, bias}, nil
+}
@@ -0,0 +1,61 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/big"
+	"testing"
+)
+
+func TestBigInts(t *testing.T) {
+	tests := []struct {
+		desc     string
+		min, max string
+	}{
+		{"bigint(-1000000000000000000000000,1000000000000000000000000)", "-1000000000000000000000000", "1000000000000000000000000"},
+		{"bigint(18446744073709551615,18446744073709551620)", "18446744073709551615", "18446744073709551620"},
+		{"bigint(-5,-5)", "-5", "-5"},
+		{"digits(30)", "100000000000000000000000000000", "999999999999999999999999999999"},
+		{"digits(1)", "0", "9"},
+		{"digits(40)|edges", "1000000000000000000000000000000000000000", "9999999999999999999999999999999999999999"},
+	}
+	for _, test := range tests {
+		min, _ := new(big.Int).SetString(test.min, 10)
+		max, _ := new(big.Int).SetString(test.max, 10)
+		distinct := make(map[string]bool)
+		for _, value := range generate(t, test.desc) {
+			x, ok := new(big.Int).SetString(value.Text, 10)
+			if value.Kind != IntKind || !ok || x.Cmp(min) < 0 || x.Cmp(max) > 0 {
+				t.Errorf("%s: %s out of bounds", test.desc, value)
+			}
+			distinct[value.Text] = true
+		}
+		if n := new(big.Int).Sub(max, min); n.Cmp(big.NewInt(samples)) > 0 && len(distinct) < samples/2 {
+			t.Errorf("%s: only %d distinct values", test.desc, len(distinct))
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"bigint(10,9)":  "invalid bounds, 10 is greater than 9",
+		"bigint(1.5,9)": "expected an integer",
+		"bigint(1)":     "bigint expects 2 arguments",
+		"digits(0)":     "number of digits must be positive",
+	})
+}
@@ -0,0 +1,150 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// pick
+
+type PickRandomGenerator struct {
+	Corpus *Corpus
+}
+
+// Generates a random line of a text file, uniformly among its lines, so that
+// lines appearing several times are drawn more often.
+func (g PickRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return StringValue(g.Corpus.Lines[r.Intn(len(g.Corpus.Lines))])
+}
+
+func (g PickRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// pick("file.txt")
+func newPickGenerator(p *Params) (RandomGenerator, error) {
+	var path string
+	if err := p.Scan(&path); err != nil {
+		return nil, err
+	}
+	corpus, err := loadCorpus(p.Arg(0), path)
+	if err != nil {
+		return nil, err
+	}
+	if len(corpus.Lines) == 0 {
+		return nil, p.Arg(0).Errorf("file has no lines")
+	}
+	return PickRandomGenerator{corpus}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// sentence
+
+type SentenceRandomGenerator struct {
+	MinWords int
+	MaxWords int
+	Corpus   *Corpus
+}
+
+// Generates a random sentence made of words of a text file, separated by
+// spaces. The number of words is drawn uniformly between the bounds, and the
+// words uniformly among the ones of the file.
+func (g SentenceRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	words := make([]string, randint(r, g.MinWords, g.MaxWords))
+	for i := range words {
+		words[i] = g.Corpus.Words[r.Intn(len(g.Corpus.Words))]
+	}
+	return StringValue(strings.Join(words, " "))
+}
+
+func (g SentenceRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// sentence(minwords,maxwords,"file.txt")
+func newSentenceGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() != 3 {
+		return nil, p.Errorf("sentence expects 3 arguments")
+	}
+	min, max, err := lengthBounds(p)
+	if err != nil {
+		return nil, err
+	}
+	path, err := p.Arg(2).Str()
+	if err != nil {
+		return nil, err
+	}
+	corpus, err := loadCorpus(p.Arg(2), path)
+	if err != nil {
+		return nil, err
+	}
+	if len(corpus.Words) == 0 && max > 0 {
+		return nil, p.Arg(2).Errorf("file has no words")
+	}
+	return SentenceRandomGenerator{min, max, corpus}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Corpora
+
+// Corpus is the content of a text file, such as a list of words, split into
+// its nonblank lines, without their surrounding spaces, and into its words,
+// that is the sequences of characters separated by spaces.
+type Corpus struct {
+	Lines []string
+	Words []string
+}
+
+// ParseCorpus splits the content of a text file into its lines and words.
+func ParseCorpus(src string) *Corpus {
+	c := &Corpus{Words: strings.Fields(src)}
+	for _, line := range strings.Split(src, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			c.Lines = append(c.Lines, line)
+		}
+	}
+	return c
+}
+
+// distinctLines lists the lines of the text file, without repetitions.
+func (c *Corpus) distinctLines() []string {
+	var lines []string
+	seen := make(map[string]bool)
+	for _, line := range c.Lines {
+		if !seen[line] {
+			seen[line] = true
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func loadCorpus(arg *Arg, path string) (*Corpus, error) {
+	content, err := loadFile("corpus", path, func(data []byte) (interface{}, error) {
+		return ParseCorpus(string(data)), nil
+	})
+	if err != nil {
+		return nil, arg.Errorf("%s", err)
+	}
+	return content.(*Corpus), nil
+}
@@ -0,0 +1,98 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseCorpus(t *testing.T) {
+	tests := []struct {
+		src   string
+		lines []string
+		words []string
+	}{
+		{"", nil, []string{}},
+		{"one\ntwo\n", []string{"one", "two"}, []string{"one", "two"}},
+		{"  red apple \r\n\n\tgreen pear\n   \n", []string{"red apple", "green pear"}, []string{"red", "apple", "green", "pear"}},
+		{"a\na\nb", []string{"a", "a", "b"}, []string{"a", "a", "b"}},
+	}
+	for _, test := range tests {
+		c := ParseCorpus(test.src)
+		if !reflect.DeepEqual(c.Lines, test.lines) || !reflect.DeepEqual(c.Words, test.words) {
+			t.Errorf("%q: got lines %q and words %q, expected %q and %q", test.src, c.Lines, c.Words, test.lines, test.words)
+		}
+	}
+	if lines := ParseCorpus("a\nb\na\nc\nb").distinctLines(); !reflect.DeepEqual(lines, []string{"a", "b", "c"}) {
+		t.Errorf("got distinct lines %q", lines)
+	}
+}
+
+func TestCorpus(t *testing.T) {
+	dir, remove := writeFiles(t, map[string]string{
+		"fruits.txt": "apple\nbanana split\napple\n\ncherry\n",
+		"empty.txt":  " \n\n",
+	})
+	defer remove()
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = dir
+
+	// Lines are drawn according to their number of occurrences.
+	counts := make(map[string]int)
+	for _, v := range generate(t, `pick("fruits.txt")`) {
+		counts[v.String()]++
+	}
+	if len(counts) != 3 || counts["apple"] < counts["cherry"] || counts["apple"] < counts["banana split"] {
+		t.Errorf("unexpected frequencies of lines %v", counts)
+	}
+	if n, ok := cardinality(build(t, `pick("fruits.txt")`)); !ok || n != 3 {
+		t.Errorf("got cardinality %d, expected 3", n)
+	}
+
+	words := map[string]bool{"apple": true, "banana": true, "split": true, "cherry": true}
+	for _, v := range generate(t, `sentence(2,4,"fruits.txt")`) {
+		fields := strings.Split(v.String(), " ")
+		if len(fields) < 2 || len(fields) > 4 {
+			t.Fatalf("sentence %q does not have between 2 and 4 words", v)
+		}
+		for _, word := range fields {
+			if !words[word] {
+				t.Fatalf("sentence %q has unknown word %q", v, word)
+			}
+		}
+	}
+	for _, v := range generate(t, `sentence(0,0,"empty.txt")`) {
+		if v.String() != "" {
+			t.Fatalf("got sentence %q, expected an empty one", v)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		`pick("empty.txt")`:           "file has no lines",
+		`pick("missing.txt")`:         "missing.txt",
+		`sentence(1,2,"empty.txt")`:   "file has no words",
+		`sentence(1,"fruits.txt")`:    "sentence expects 3 arguments",
+		`sentence(3,2,"fruits.txt")`:  "invalid bounds, 3 is greater than 2",
+		`sentence(1,2,"missing.txt")`: "missing.txt",
+		`sentence(-1,2,"fruits.txt")`: "length cannot be negative",
+	})
+}
@@ -0,0 +1,303 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"math/big"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Partitioner is implemented by generators whose values can be split into a
+// few parts, such as the values of an enumeration or ranges of numbers, so
+// that combinations of parts of several arguments can be covered.
+type Partitioner interface {
+	Partition(buckets int) []RandomGenerator
+}
+
+// Partition splits the values of a boolean into false and true.
+func (g BoolRandomGenerator) Partition(buckets int) []RandomGenerator {
+	return []RandomGenerator{constant{BoolValue(false)}, constant{BoolValue(true)}}
+}
+
+// Partition splits the values of an enumeration into the distinct values that
+// can be drawn.
+func (g EnumRandomGenerator) Partition(buckets int) []RandomGenerator {
+	values, _ := g.Enumerate(len(g.Values))
+	parts := make([]RandomGenerator, len(values))
+	for i, value := range values {
+		parts[i] = constant{value}
+	}
+	return parts
+}
+
+// Partition splits the range of integers into buckets of equal sizes.
+func (g IntRandomGenerator) Partition(buckets int) []RandomGenerator {
+	size := g.Max - g.Min + 1
+	if size > 0 && size < buckets {
+		buckets = size
+	}
+
+	parts := make([]RandomGenerator, buckets)
+	min := g.Min
+	for i := range parts {
+		max := g.Max
+		if i < buckets-1 {
+			max = g.Min + int((float64(g.Max)-float64(g.Min))*float64(i+1)/float64(buckets))
+		}
+		parts[i] = IntRandomGenerator{Min: min, Max: max}
+		min = max + 1
+	}
+	return parts
+}
+
+// Partition splits the range of integers into buckets of equal sizes.
+func (g BigIntRandomGenerator) Partition(buckets int) []RandomGenerator {
+	n := new(big.Int).Sub(g.Max, g.Min)
+	if n.Cmp(big.NewInt(int64(buckets))) < 0 {
+		buckets = int(n.Int64()) + 1
+	}
+
+	parts := make([]RandomGenerator, buckets)
+	min := g.Min
+	for i := range parts {
+		max := g.Max
+		if i < buckets-1 {
+			max = new(big.Int).Mul(n, big.NewInt(int64(i+1)))
+			max.Quo(max, big.NewInt(int64(buckets)))
+			max.Add(max, g.Min)
+		}
+		parts[i] = BigIntRandomGenerator{Min: min, Max: max}
+		min = new(big.Int).Add(max, big.NewInt(1))
+	}
+	return parts
+}
+
+// Partition splits the range of numbers into buckets of equal widths.
+func (g FloatRandomGenerator) Partition(buckets int) []RandomGenerator {
+	parts := make([]RandomGenerator, buckets)
+	width := (g.Max - g.Min) / float64(buckets)
+	for i := range parts {
+		parts[i] = FloatRandomGenerator{Min: g.Min + float64(i)*width, Max: g.Min + float64(i+1)*width, Precision: g.Precision}
+	}
+	return parts
+}
+
+// Partition splits the values into the missing value and the other ones.
+func (g OptionalRandomGenerator) Partition(buckets int) []RandomGenerator {
+	if g.P == 0 || g.P == 1 {
+		return nil
+	}
+	return []RandomGenerator{constant{NullValue()}, g.Generator}
+}
+
+// Partition splits the values according to the generator producing them.
+func (g OneOfRandomGenerator) Partition(buckets int) []RandomGenerator {
+	if alternatives := g.alternatives(); len(alternatives) > 1 {
+		return alternatives
+	}
+	return nil
+}
+
+// constant always generates the same value.
+type constant struct {
+	Value Value
+}
+
+func (g constant) GenerateValue(r *rand.Rand) Value {
+	return g.Value
+}
+
+func (g constant) Generate(r *rand.Rand) string {
+	return g.Value.String()
+}
+
+// CoverInputs generates test inputs covering all the combinations of parts of
+// any strength arguments, among the ones whose generator is a Partitioner,
+// such as all the pairs of values for a strength of two. Rows are built one
+// at a time, greedily choosing the parts covering the most combinations not
+// covered yet, and the values of the other arguments are drawn at random.
+// Values are drawn again when a dependent argument cannot be resolved, up to
+// a maximum number of attempts.
+func CoverInputs(r *rand.Rand, gens []Argument, order []int, strength int, buckets int) ([][]Value, error) {
+	if strength < 1 || buckets < 1 {
+		return nil, fmt.Errorf("strength and number of buckets must be positive")
+	}
+
+	// Parts of the arguments to cover.
+	var params []int
+	parts := make(map[int][]RandomGenerator)
+	for i, g := range gens {
+		if len(g.Dependencies()) > 0 {
+			continue
+		}
+		generator, err := g.Resolve(nil)
+		if err != nil {
+			return nil, fmt.Errorf("argument $%d: %s", i+1, err)
+		}
+		if partitioner, ok := generator.(Partitioner); ok {
+			if p := partitioner.Partition(buckets); len(p) > 0 {
+				params = append(params, i)
+				parts[i] = p
+			}
+		}
+	}
+	if len(params) == 0 {
+		return nil, nil
+	}
+	if strength > len(params) {
+		strength = len(params)
+	}
+
+	// Combinations of parts not covered yet, identified by their keys.
+	uncovered := make(map[string]bool)
+	for _, subset := range subsets(params, strength) {
+		levels := make(map[int]int, strength)
+		var visit func(k int)
+		visit = func(k int) {
+			if k == len(subset) {
+				uncovered[combinationKey(subset, levels)] = true
+				return
+			}
+			for l := range parts[subset[k]] {
+				levels[subset[k]] = l
+				visit(k + 1)
+			}
+		}
+		visit(0)
+	}
+
+	var rows [][]Value
+	for len(uncovered) > 0 {
+		// Start from a combination not covered yet, whose keys are sorted for
+		// the rows to be reproducible.
+		keys := make([]string, 0, len(uncovered))
+		for key := range uncovered {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		levels := parseCombinationKey(keys[0])
+
+		// Choose the parts of the other arguments in a random order.
+		fixed := make([]int, 0, len(params))
+		for _, i := range params {
+			if _, ok := levels[i]; ok {
+				fixed = append(fixed, i)
+			}
+		}
+		for _, k := range r.Perm(len(params)) {
+			i := params[k]
+			if _, ok := levels[i]; ok {
+				continue
+			}
+			best, bestScore := 0, -1
+			for l := range parts[i] {
+				levels[i] = l
+				score := 0
+				for _, subset := range subsets(fixed, strength-1) {
+					if uncovered[combinationKey(append(subset, i), levels)] {
+						score++
+					}
+				}
+				if score > bestScore {
+					best, bestScore = l, score
+				}
+			}
+			levels[i] = best
+			fixed = append(fixed, i)
+		}
+
+		for _, subset := range subsets(params, strength) {
+			delete(uncovered, combinationKey(subset, levels))
+		}
+
+		// Draw the values of the row, again when a dependent argument cannot
+		// be resolved with the values drawn for the ones it refers to, such
+		// as int(0,len($1)-1) with an empty $1.
+		var row []Value
+		var err error
+		for attempts := 0; row == nil && attempts < maxAttempts; attempts++ {
+			row, err = drawRow(r, gens, order, parts, levels)
+		}
+		if row == nil {
+			return nil, err
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// drawRow draws the values of a row, in the given order, from the chosen parts
+// of the covered arguments and at random for the other ones.
+func drawRow(r *rand.Rand, gens []Argument, order []int, parts map[int][]RandomGenerator, levels map[int]int) ([]Value, error) {
+	row := make([]Value, len(gens))
+	for _, i := range order {
+		var g RandomGenerator
+		if l, ok := levels[i]; ok {
+			g = parts[i][l]
+		} else if resolved, err := gens[i].Resolve(row); err == nil {
+			g = resolved
+		} else {
+			return nil, fmt.Errorf("argument $%d: %s", i+1, err)
+		}
+		row[i] = GenerateValue(g, r)
+	}
+	return row, nil
+}
+
+// subsets lists the subsets of k elements of a list, in order.
+func subsets(elements []int, k int) [][]int {
+	if k == 0 {
+		return [][]int{{}}
+	}
+	var result [][]int
+	for i := 0; i+k <= len(elements); i++ {
+		for _, subset := range subsets(elements[i+1:], k-1) {
+			result = append(result, append([]int{elements[i]}, subset...))
+		}
+	}
+	return result
+}
+
+// combinationKey identifies the combination of the parts of some arguments,
+// such as 0=1,3=0 for the second part of $1 and the first part of $4.
+func combinationKey(args []int, levels map[int]int) string {
+	sorted := append([]int{}, args...)
+	sort.Ints(sorted)
+	data := make([]string, len(sorted))
+	for k, i := range sorted {
+		data[k] = strconv.Itoa(i) + "=" + strconv.Itoa(levels[i])
+	}
+	return strings.Join(data, ",")
+}
+
+func parseCombinationKey(key string) map[int]int {
+	levels := make(map[int]int)
+	for _, data := range strings.Split(key, ",") {
+		parts := strings.Split(data, "=")
+		i, _ := strconv.Atoi(parts[0])
+		l, _ := strconv.Atoi(parts[1])
+		levels[i] = l
+	}
+	return levels
+}
@@ -0,0 +1,140 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// part finds the part of a partition a value was drawn from.
+func part(parts []RandomGenerator, v Value) int {
+	for l, p := range parts {
+		switch p := p.(type) {
+		case constant:
+			if p.Value.String() == v.String() {
+				return l
+			}
+		case IntRandomGenerator:
+			if x, _ := v.Number(); p.Min <= int(x) && int(x) <= p.Max {
+				return l
+			}
+		}
+	}
+	return -1
+}
+
+func TestCoverInputs(t *testing.T) {
+	descs := []string{"bool", "enum(a,b,c)", "str(1,3)", "int(0,99)", "int(0,len($3))"}
+	gens, err := BuildGenerators(descs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, _ := EvaluationOrder(gens)
+	params := []int{0, 1, 3}
+	parts := make(map[int][]RandomGenerator)
+	for _, i := range params {
+		g, _ := gens[i].Resolve(nil)
+		parts[i] = g.(Partitioner).Partition(4)
+	}
+
+	for strength, most := range map[int]int{1: 4, 2: 16, 3: 24} {
+		rows, err := CoverInputs(rand.New(rand.NewSource(1)), gens, order, strength, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rows) > most {
+			t.Errorf("strength %d: %d rows, expected at most %d", strength, len(rows), most)
+		}
+
+		covered := make(map[string]bool)
+		for _, row := range rows {
+			if x, _ := row[4].Number(); int(x) > row[2].Len() {
+				t.Errorf("strength %d: dependent argument %s out of bounds in %v", strength, row[4], row)
+			}
+			for _, subset := range subsets(params, strength) {
+				key := fmt.Sprint(subset)
+				for _, i := range subset {
+					key += fmt.Sprintf(" %d", part(parts[i], row[i]))
+				}
+				covered[key] = true
+			}
+		}
+		for _, subset := range subsets(params, strength) {
+			total := 1
+			for _, i := range subset {
+				total *= len(parts[i])
+			}
+			count := 0
+			for key := range covered {
+				if len(key) > len(fmt.Sprint(subset)) && key[:len(fmt.Sprint(subset))] == fmt.Sprint(subset) {
+					count++
+				}
+			}
+			if count != total {
+				t.Errorf("strength %d: %d of the %d combinations of %v covered", strength, count, total, subset)
+			}
+		}
+	}
+
+	// Rows are drawn again when a dependent argument cannot be resolved, and
+	// given up when it never can.
+	tests := []struct {
+		descs []string
+		ok    bool
+		check func(row []Value, x int) bool
+	}{
+		{[]string{"bool", "array(0,2)[int(0,9)]", "int(0,len($2)-1)"}, true, func(row []Value, x int) bool {
+			return x >= 0 && x < row[1].Len()
+		}},
+		{[]string{"enum(a,b,c)", "str(0,1)", "array(0,1)[bool]", "int(len($2),len($3))"}, true, func(row []Value, x int) bool {
+			return x >= row[1].Len() && x <= row[2].Len()
+		}},
+		{[]string{"bool", "array(0,0)[int(0,9)]", "int(0,len($2)-1)"}, false, nil},
+	}
+	for _, test := range tests {
+		gens, err := BuildGenerators(test.descs...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		order, _ := EvaluationOrder(gens)
+		rows, err := CoverInputs(rand.New(rand.NewSource(1)), gens, order, 2, 4)
+		if !test.ok {
+			if err == nil || !strings.Contains(err.Error(), "argument $3") {
+				t.Errorf("%v: got error %v, expected an error for argument $3", test.descs, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%v: %s", test.descs, err)
+		}
+		for _, row := range rows {
+			if x, _ := row[len(row)-1].Number(); !test.check(row, int(x)) {
+				t.Errorf("%v: dependent argument out of bounds in %v", test.descs, row)
+			}
+		}
+	}
+
+	if _, err := CoverInputs(rand.New(rand.NewSource(1)), gens, order, 0, 4); err == nil {
+		t.Error("expected an error for a null strength")
+	}
+}
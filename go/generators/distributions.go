@@ -0,0 +1,245 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Clamp contains the bounds to which the numbers sampled from a statistical
+// distribution are restricted.
+type Clamp struct {
+	Min float64
+	Max float64
+}
+
+func (c *Clamp) apply(x float64) float64 {
+	if c == nil {
+		return x
+	}
+	return math.Max(c.Min, math.Min(c.Max, x))
+}
+
+func (c *Clamp) applyInt(k int) int {
+	if c == nil {
+		return k
+	}
+	if x := float64(k); x < c.Min {
+		return int(math.Ceil(c.Min))
+	} else if x > c.Max {
+		return int(math.Floor(c.Max))
+	}
+	return k
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// normal
+
+type NormalRandomGenerator struct {
+	Mean      float64
+	StdDev    float64
+	Clamp     *Clamp
+	Precision int // decimals, six if negative
+}
+
+// Generates a random floating-point number following a normal distribution.
+func (g NormalRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return FloatValue(g.Clamp.apply(g.Mean+r.NormFloat64()*g.StdDev), g.Precision)
+}
+
+func (g NormalRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// normal(mean,stddev)
+func newNormalGenerator(p *Params) (RandomGenerator, error) {
+	var mean, stddev float64
+	if err := p.Scan(&mean, &stddev); err != nil {
+		return nil, err
+	}
+	if stddev < 0 {
+		return nil, p.Arg(1).Errorf("standard deviation cannot be negative")
+	}
+	bounds, err := clamp(p)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := precision(p)
+	if err != nil {
+		return nil, err
+	}
+	return NormalRandomGenerator{mean, stddev, bounds, decimals}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// exponential
+
+type ExponentialRandomGenerator struct {
+	Rate      float64
+	Clamp     *Clamp
+	Precision int // decimals, six if negative
+}
+
+// Generates a random floating-point number following an exponential distribution.
+func (g ExponentialRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return FloatValue(g.Clamp.apply(r.ExpFloat64()/g.Rate), g.Precision)
+}
+
+func (g ExponentialRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// exponential(rate)
+func newExponentialGenerator(p *Params) (RandomGenerator, error) {
+	var rate float64
+	if err := p.Scan(&rate); err != nil {
+		return nil, err
+	}
+	if rate <= 0 {
+		return nil, p.Arg(0).Errorf("rate must be positive")
+	}
+	bounds, err := clamp(p)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := precision(p)
+	if err != nil {
+		return nil, err
+	}
+	return ExponentialRandomGenerator{rate, bounds, decimals}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// poisson
+
+// Mean from which the Poisson distribution is sampled with the transformed
+// rejection method rather than by multiplying uniform numbers, whose number
+// grows with the mean.
+const poissonRejectionThreshold = 10
+
+type PoissonRandomGenerator struct {
+	Mean  float64
+	Clamp *Clamp
+}
+
+// Generates a random integer number following a Poisson distribution, sampled
+// exactly with Knuth's algorithm for small means and with Hörmann's transformed
+// rejection with squeeze (PTRS) for larger ones.
+func (g PoissonRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	var k int
+	if g.Mean >= poissonRejectionThreshold {
+		k = poissonPTRS(r, g.Mean)
+	} else {
+		// Knuth's algorithm, multiplying uniform numbers until exp(-mean) is reached.
+		limit := math.Exp(-g.Mean)
+		for p := r.Float64(); p > limit; p *= r.Float64() {
+			k++
+		}
+	}
+	return IntValue(g.Clamp.applyInt(k))
+}
+
+// poissonPTRS samples a Poisson distribution with the transformed rejection
+// method with squeeze of W. Hörmann, "The transformed rejection method for
+// generating Poisson random variables", 1993, for means of at least 10.
+func poissonPTRS(r *rand.Rand, mean float64) int {
+	slam, loglam := math.Sqrt(mean), math.Log(mean)
+	b := 0.931 + 2.53*slam
+	a := -0.059 + 0.02483*b
+	invalpha := 1.1239 + 1.1328/(b-3.4)
+	vr := 0.9277 - 3.6224/(b-2)
+	for {
+		u, v := r.Float64()-0.5, r.Float64()
+		us := 0.5 - math.Abs(u)
+		k := math.Floor((2*a/us+b)*u + mean + 0.43)
+		if us >= 0.07 && v <= vr {
+			return int(k)
+		}
+		if k < 0 || (us < 0.013 && v > us) {
+			continue
+		}
+		lg, _ := math.Lgamma(k + 1)
+		if math.Log(v)+math.Log(invalpha)-math.Log(a/(us*us)+b) <= -mean+k*loglam-lg {
+			return int(k)
+		}
+	}
+}
+
+func (g PoissonRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// poisson(mean)
+func newPoissonGenerator(p *Params) (RandomGenerator, error) {
+	var mean float64
+	if err := p.Scan(&mean); err != nil {
+		return nil, err
+	}
+	if mean <= 0 {
+		return nil, p.Arg(0).Errorf("mean must be positive")
+	}
+	bounds, err := intClamp(p)
+	if err != nil {
+		return nil, err
+	}
+	return PoissonRandomGenerator{mean, bounds}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// geometric
+
+type GeometricRandomGenerator struct {
+	P     float64
+	Clamp *Clamp
+}
+
+// Generates a random integer number following a geometric distribution, that is
+// the number of trials needed to get a first success with a given probability.
+func (g GeometricRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	k := 1
+	if g.P < 1 {
+		k = int(math.Ceil(math.Log(1-r.Float64()) / math.Log(1-g.P)))
+		if k < 1 {
+			k = 1
+		}
+	}
+	return IntValue(g.Clamp.applyInt(k))
+}
+
+func (g GeometricRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// geometric(p)
+func newGeometricGenerator(p *Params) (RandomGenerator, error) {
+	var prob float64
+	if err := p.Scan(&prob); err != nil {
+		return nil, err
+	}
+	if prob <= 0 || prob > 1 {
+		return nil, p.Arg(0).Errorf("probability must be comprised between 0 (excluded) and 1")
+	}
+	bounds, err := intClamp(p)
+	if err != nil {
+		return nil, err
+	}
+	return GeometricRandomGenerator{prob, bounds}, nil
+}
@@ -0,0 +1,96 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDistributions(t *testing.T) {
+	const draws = 4000
+	tests := []struct {
+		desc     string
+		kind     Kind
+		mean     float64
+		min, max float64
+	}{
+		{"normal(10,2)", FloatKind, 10, math.Inf(-1), math.Inf(1)},
+		{"normal(0,10)|clamp(-1,1)", FloatKind, 0, -1, 1},
+		{"exponential(2)", FloatKind, 0.5, 0, math.Inf(1)},
+		{"exponential(1)|clamp(0.5,2)|precision(1)", FloatKind, 0.97, 0.5, 2},
+		{"poisson(4)", IntKind, 4, 0, math.Inf(1)},
+		{"poisson(200)", IntKind, 200, 0, math.Inf(1)},
+		{"poisson(5)|clamp(2.5,6)", IntKind, 4.7, 3, 6},
+		{"poisson(50)|clamp(2.5,3.5)", IntKind, 3, 3, 3},
+		{"geometric(0.25)", IntKind, 4, 1, math.Inf(1)},
+		{"geometric(1)", IntKind, 1, 1, 1},
+	}
+	for _, test := range tests {
+		g := build(t, test.desc)
+		r := rand.New(rand.NewSource(1))
+		total := 0.0
+		for i := 0; i < draws; i++ {
+			value := GenerateValue(g, r)
+			x, ok := value.Number()
+			if !ok || value.Kind != test.kind || x < test.min || x > test.max {
+				t.Errorf("%s: unexpected %s", test.desc, value)
+			}
+			total += x
+		}
+		if mean := total / draws; math.Abs(mean-test.mean) > 0.05*math.Max(1, test.mean) {
+			t.Errorf("%s: mean %.3f, expected %.3f", test.desc, mean, test.mean)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"normal(0,-1)":                   "standard deviation cannot be negative",
+		"exponential(0)":                 "rate must be positive",
+		"poisson(0)":                     "mean must be positive",
+		"geometric(0)":                   "probability must be comprised between 0 (excluded) and 1",
+		"geometric(1.5)":                 "probability must be comprised between 0 (excluded) and 1",
+		"normal(0,1)|clamp(1,-1)":        "invalid bounds, 1 is greater than -1",
+		"normal(0,1)|clamp(1)":           "clamp expects 2 arguments",
+		"poisson(1)|precision(2)":        "unknown modifier 'precision' for poisson",
+		"poisson(50)|clamp(0.5,0.7)":     "no integer between 0.5 and 0.7",
+		"geometric(0.5)|clamp(1.2,1.8)":  "no integer between 1.2 and 1.8",
+		"array(0,2)[normal(0,1)]|unique": "cannot guarantee distinct values",
+	})
+}
+
+func TestPoissonVariance(t *testing.T) {
+	const draws = 20000
+	for _, mean := range []float64{3, 10, 45, 200, 5000} {
+		g := PoissonRandomGenerator{Mean: mean}
+		r := rand.New(rand.NewSource(1))
+		total, squares := 0.0, 0.0
+		for i := 0; i < draws; i++ {
+			x, _ := g.GenerateValue(r).Number()
+			total += x
+			squares += x * x
+		}
+		m := total / draws
+		variance := squares/draws - m*m
+		if math.Abs(m-mean) > 0.02*mean || math.Abs(variance-mean) > 0.05*mean {
+			t.Errorf("poisson(%g): mean %.3f and variance %.3f, expected %g for both", mean, m, variance, mean)
+		}
+	}
+}
@@ -0,0 +1,425 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Enumerator is implemented by generators able to list all the values they can
+// generate, for small domains. Enumerate returns false when there are more
+// values than the given limit.
+type Enumerator interface {
+	Enumerate(limit int) ([]Value, bool)
+}
+
+// Enumerate lists all the values of a generator, provided it is an Enumerator
+// and that there are at most limit values.
+func Enumerate(g RandomGenerator, limit int) ([]Value, bool) {
+	if enumerator, ok := g.(Enumerator); ok {
+		return enumerator.Enumerate(limit)
+	}
+	return nil, false
+}
+
+// EnumerateInputs lists all the combinations of values of the arguments, which
+// are enumerated in the given order so that dependent generators are resolved
+// with each combination of the arguments they refer to. It returns false when
+// there are more combinations than the given limit, or when an argument cannot
+// be enumerated.
+func EnumerateInputs(gens []Argument, order []int, limit int) ([][]Value, bool) {
+	var rows [][]Value
+	var visit func(k int, args []Value) bool
+	visit = func(k int, args []Value) bool {
+		if k == len(order) {
+			rows = append(rows, append([]Value{}, args...))
+			return len(rows) <= limit
+		}
+
+		// Combinations for which a dependent generator cannot be resolved,
+		// such as int(0,len($1)-1) with an empty $1, are skipped.
+		i := order[k]
+		g, err := gens[i].Resolve(args)
+		if err != nil {
+			return true
+		}
+		values, ok := Enumerate(g, limit)
+		if !ok {
+			return false
+		}
+		for _, value := range values {
+			args[i] = value
+			if !visit(k+1, args) {
+				return false
+			}
+		}
+		args[i] = Value{}
+		return true
+	}
+
+	if !visit(0, make([]Value, len(gens))) {
+		return nil, false
+	}
+	return rows, true
+}
+
+// sequences lists the sequences of the given length whose next value is drawn
+// from the choices given the previous values, provided there are at most limit
+// sequences.
+func sequences(length int, choices func(prefix []Value) []Value, limit int) ([][]Value, bool) {
+	var result [][]Value
+	var visit func(prefix []Value) bool
+	visit = func(prefix []Value) bool {
+		if len(prefix) == length {
+			result = append(result, append([]Value{}, prefix...))
+			return len(result) <= limit
+		}
+		for _, value := range choices(prefix) {
+			if !visit(append(prefix, value)) {
+				return false
+			}
+		}
+		return true
+	}
+
+	if !visit(make([]Value, 0, length)) {
+		return nil, false
+	}
+	return result, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Values
+
+// Enumerate lists the integers between the bounds.
+func (g IntRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	if g.Max-g.Min < 0 || g.Max-g.Min >= limit {
+		return nil, false
+	}
+	values := make([]Value, 0, g.Max-g.Min+1)
+	for i := g.Min; i <= g.Max; i++ {
+		values = append(values, IntValue(i))
+	}
+	return values, true
+}
+
+// Enumerate lists the integers between the bounds.
+func (g BigIntRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	n := new(big.Int).Sub(g.Max, g.Min)
+	if n.Cmp(big.NewInt(int64(limit))) >= 0 {
+		return nil, false
+	}
+	var values []Value
+	for i := new(big.Int).Set(g.Min); i.Cmp(g.Max) <= 0; i.Add(i, big.NewInt(1)) {
+		values = append(values, BigIntValue(i))
+	}
+	return values, true
+}
+
+// Enumerate lists false and true.
+func (g BoolRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	if limit < 2 {
+		return nil, false
+	}
+	return []Value{BoolValue(false), BoolValue(true)}, true
+}
+
+// Enumerate lists the distinct values of the enumeration that can be drawn.
+func (g EnumRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	var texts []string
+	for i, value := range g.Values {
+		if (g.Weights == nil || g.Weights[i] > 0) && !contains(texts, value) {
+			texts = append(texts, value)
+		}
+	}
+	if len(texts) > limit {
+		return nil, false
+	}
+
+	values := make([]Value, len(texts))
+	for i, text := range texts {
+		values[i] = literalValue(text)
+	}
+	return values, true
+}
+
+// Enumerate lists the distinct lines of the text file.
+func (g PickRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	lines := g.Corpus.distinctLines()
+	if len(lines) > limit {
+		return nil, false
+	}
+
+	values := make([]Value, len(lines))
+	for i, line := range lines {
+		values[i] = StringValue(line)
+	}
+	return values, true
+}
+
+// Enumerate lists the strings over the alphabet, by increasing length.
+func (g StringRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	if n, ok := cardinality(g); !ok || n > limit {
+		return nil, false
+	}
+
+	alphabet := g.Alphabet
+	if alphabet == nil {
+		alphabet = characterClasses["alnum"]
+	}
+	var chars []Value
+	for i := 0; i < len(alphabet); i += 2 {
+		for c := alphabet[i]; c <= alphabet[i+1]; c++ {
+			chars = append(chars, StringValue(string(c)))
+		}
+	}
+
+	var values []Value
+	for length := g.MinLength; length <= g.MaxLength; length++ {
+		strs, ok := sequences(length, func([]Value) []Value { return chars }, limit-len(values))
+		if !ok {
+			return nil, false
+		}
+		for _, str := range strs {
+			var sb strings.Builder
+			for _, c := range str {
+				sb.WriteString(c.Text)
+			}
+			values = append(values, StringValue(sb.String()))
+		}
+	}
+	return values, true
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Collections
+
+// Enumerate lists the arrays by increasing length, keeping only the ones with
+// distinct and sorted elements when required.
+func (g ArrayRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	elements, ok := Enumerate(g.Generator, limit)
+	if !ok {
+		return nil, false
+	}
+
+	choices := func(prefix []Value) []Value {
+		var values []Value
+		for _, value := range elements {
+			if g.Unique && containsValue(prefix, value) {
+				continue
+			}
+			if n := len(prefix); n > 0 {
+				if (g.Sorted && !g.Descending && lessValues(value, prefix[n-1])) || (g.Descending && lessValues(prefix[n-1], value)) {
+					continue
+				}
+			}
+			values = append(values, value)
+		}
+		return values
+	}
+
+	var values []Value
+	for length := g.MinLength; length <= g.MaxLength; length++ {
+		arrays, ok := sequences(length, choices, limit-len(values))
+		if !ok {
+			return nil, false
+		}
+		for _, array := range arrays {
+			values = append(values, ArrayValue(array))
+		}
+	}
+	return values, true
+}
+
+// Enumerate lists the permutations in lexicographic order.
+func (g PermutationRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	numbers := make([]Value, g.N)
+	for i := range numbers {
+		numbers[i] = IntValue(i + 1)
+	}
+
+	permutations, ok := sequences(g.N, func(prefix []Value) []Value {
+		var values []Value
+		for _, value := range numbers {
+			if !containsValue(prefix, value) {
+				values = append(values, value)
+			}
+		}
+		return values
+	}, limit)
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]Value, len(permutations))
+	for i, permutation := range permutations {
+		values[i] = ArrayValue(permutation)
+	}
+	return values, true
+}
+
+// Enumerate lists the maps by increasing number of entries, whose keys are
+// taken in the order in which they are enumerated.
+func (g MapRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	keys, ok := Enumerate(g.KeyGenerator, limit)
+	if !ok {
+		return nil, false
+	}
+	values, ok := Enumerate(g.ValueGenerator, limit)
+	if !ok {
+		return nil, false
+	}
+
+	// Entries are tuples made of the index of the key and of the value.
+	var entries []Value
+	for i := range keys {
+		for _, value := range values {
+			entries = append(entries, TupleValue([]Value{IntValue(i), value}))
+		}
+	}
+	choices := func(prefix []Value) []Value {
+		if len(prefix) == 0 {
+			return entries
+		}
+		i, _ := strconv.Atoi(prefix[len(prefix)-1].Elems[0].Text)
+		return entries[(i+1)*len(values):]
+	}
+
+	var maps []Value
+	for length := g.MinLength; length <= g.MaxLength && length <= len(keys); length++ {
+		data, ok := sequences(length, choices, limit-len(maps))
+		if !ok {
+			return nil, false
+		}
+		for _, entries := range data {
+			mapKeys := make([]Value, len(entries))
+			mapValues := make([]Value, len(entries))
+			for j, entry := range entries {
+				i, _ := strconv.Atoi(entry.Elems[0].Text)
+				mapKeys[j], mapValues[j] = keys[i], entry.Elems[1]
+			}
+			maps = append(maps, MapValue(mapKeys, mapValues))
+		}
+	}
+	return maps, true
+}
+
+// Enumerate lists the combinations of values of the components.
+func (g TupleRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	data, ok := enumerateProduct(g.Generators, limit)
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]Value, len(data))
+	for i, components := range data {
+		values[i] = TupleValue(components)
+	}
+	return values, true
+}
+
+// Enumerate lists the combinations of values of the fields.
+func (g RecordRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	data, ok := enumerateProduct(g.Generators, limit)
+	if !ok {
+		return nil, false
+	}
+
+	values := make([]Value, len(data))
+	for i, fields := range data {
+		values[i] = RecordValue(g.Names, fields)
+	}
+	return values, true
+}
+
+func enumerateProduct(gens []RandomGenerator, limit int) ([][]Value, bool) {
+	lists := make([][]Value, len(gens))
+	for i, generator := range gens {
+		values, ok := Enumerate(generator, limit)
+		if !ok {
+			return nil, false
+		}
+		lists[i] = values
+	}
+	return sequences(len(lists), func(prefix []Value) []Value {
+		return lists[len(prefix)]
+	}, limit)
+}
+
+func containsValue(values []Value, value Value) bool {
+	for _, v := range values {
+		if v.String() == value.String() {
+			return true
+		}
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Optional values
+
+// Enumerate lists the missing value.
+func (g NullRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	if limit < 1 {
+		return nil, false
+	}
+	return []Value{NullValue()}, true
+}
+
+// Enumerate lists the missing value followed by the values of the generator.
+func (g OptionalRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	var values []Value
+	if g.P > 0 {
+		values = append(values, NullValue())
+	}
+	if g.P < 1 {
+		others, ok := Enumerate(g.Generator, limit)
+		if !ok {
+			return nil, false
+		}
+		values = append(values, others...)
+	}
+	if len(values) > limit {
+		return nil, false
+	}
+	return values, true
+}
+
+// Enumerate lists the distinct values of the generators that can be chosen.
+func (g OneOfRandomGenerator) Enumerate(limit int) ([]Value, bool) {
+	var values []Value
+	for _, generator := range g.alternatives() {
+		others, ok := Enumerate(generator, limit)
+		if !ok {
+			return nil, false
+		}
+		for _, value := range others {
+			if !containsValue(values, value) {
+				values = append(values, value)
+			}
+		}
+		if len(values) > limit {
+			return nil, false
+		}
+	}
+	return values, true
+}
@@ -0,0 +1,92 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"testing"
+)
+
+func TestEnumerate(t *testing.T) {
+	tests := []struct {
+		desc  string
+		count int
+	}{
+		{"int(-2,2)", 5},
+		{"bool", 2},
+		{"enum(a,b,a,c:0)", 2},
+		{`str(0,2,"ab")`, 7},
+		{"tuple(bool,int(1,3))", 6},
+		{"array(0,2)[bool]", 7},
+		{"array(2,2)[int(1,3)]|unique", 6},
+		{"array(2,2)[int(1,3)]|sorted|unique", 3},
+		{"permutation(3)", 6},
+		{"optional(0.5)[bool]", 3},
+		{"map(0,1)[int(1,2)->bool]", 5},
+		{"record{a:bool,b:enum(x,y z)}", 4},
+		{"bigint(99999999999999999999,100000000000000000002)", 4},
+		{"oneof(bool,int(1,2),null)", 5},
+	}
+	for _, test := range tests {
+		values, ok := Enumerate(build(t, test.desc), 100)
+		if !ok || len(values) != test.count {
+			t.Errorf("%s: enumerated %d values (%v), expected %d", test.desc, len(values), ok, test.count)
+			continue
+		}
+		seen := make(map[string]bool)
+		for _, value := range values {
+			if seen[value.String()] {
+				t.Errorf("%s: %v enumerated twice", test.desc, value)
+			}
+			seen[value.String()] = true
+		}
+		if _, ok := Enumerate(build(t, test.desc), test.count-1); ok {
+			t.Errorf("%s: enumerated more values than the limit", test.desc)
+		}
+	}
+
+	for _, desc := range []string{"float(0,1)", "int(0,1000000000)", "str(0,20)", "array(0,3)[int(0,99)]", "regex(`a+`)"} {
+		if _, ok := Enumerate(build(t, desc), 1000); ok {
+			t.Errorf("%s: should not be enumerated", desc)
+		}
+	}
+}
+
+func TestEnumerateInputs(t *testing.T) {
+	gens, err := BuildGenerators("array(0,2)[bool]", "int(0,len($1)-1)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, _ := EvaluationOrder(gens)
+
+	// The empty array has no valid index, and is skipped.
+	rows, ok := EnumerateInputs(gens, order, 100)
+	if !ok || len(rows) != 10 {
+		t.Fatalf("enumerated %d rows (%v), expected 10", len(rows), ok)
+	}
+	for _, row := range rows {
+		if x, _ := row[1].Number(); int(x) >= row[0].Len() {
+			t.Errorf("invalid row %v", row)
+		}
+	}
+
+	if _, ok := EnumerateInputs(gens, order, 9); ok {
+		t.Error("enumerated more rows than the limit")
+	}
+}
@@ -0,0 +1,500 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Expressions
+
+// Functions that can be used in expressions, with their number of arguments.
+var functions = map[string]int{
+	"len": 1,
+	"sum": 1,
+	"abs": 1,
+	"min": 2,
+	"max": 2,
+}
+
+// isExpr checks whether a node is an expression to evaluate rather than a
+// literal or a generator descriptor.
+func isExpr(node *Node) bool {
+	switch node.Kind {
+	case RefNode, OperatorNode:
+		return true
+	case GeneratorNode:
+		_, ok := functions[node.Value]
+		return ok
+	}
+	return false
+}
+
+// evaluate evaluates an expression, given the values of the arguments it may
+// refer to.
+func evaluate(node *Node, args []Value) (float64, error) {
+	switch node.Kind {
+	case NumberNode:
+		return strconv.ParseFloat(node.Value, 64)
+
+	case RefNode:
+		value, err := refValue(node, args)
+		if err != nil {
+			return 0, err
+		}
+		x, ok := value.Number()
+		if !ok {
+			return 0, errorf(node.Col, "$%s is not a number", node.Value)
+		}
+		return x, nil
+
+	case OperatorNode:
+		// Logical operators only evaluate their right operand when needed,
+		// so that it can be guarded by the left one, as in $2!=0 && $1%$2==0.
+		if node.Value == "&&" || node.Value == "||" {
+			x, err := evaluate(node.Args[0], args)
+			if err != nil {
+				return 0, err
+			}
+			if (x != 0) == (node.Value == "||") {
+				return truth(x != 0), nil
+			}
+			y, err := evaluate(node.Args[1], args)
+			if err != nil {
+				return 0, err
+			}
+			return truth(y != 0), nil
+		}
+
+		operands := make([]float64, len(node.Args))
+		for i, arg := range node.Args {
+			x, err := evaluate(arg, args)
+			if err != nil {
+				return 0, err
+			}
+			operands[i] = x
+		}
+		if len(operands) == 1 {
+			if node.Value == "!" {
+				return truth(operands[0] == 0), nil
+			}
+			return -operands[0], nil
+		}
+		x, y := operands[0], operands[1]
+		switch node.Value {
+		case "+":
+			return x + y, nil
+		case "-":
+			return x - y, nil
+		case "*":
+			return x * y, nil
+		case "<":
+			return truth(x < y), nil
+		case "<=":
+			return truth(x <= y), nil
+		case ">":
+			return truth(x > y), nil
+		case ">=":
+			return truth(x >= y), nil
+		case "==":
+			return truth(x == y), nil
+		case "!=":
+			return truth(x != y), nil
+		}
+		if y == 0 {
+			return 0, errorf(node.Col, "division by zero")
+		}
+		if node.Value == "%" {
+			return math.Mod(x, y), nil
+		}
+		if x == math.Trunc(x) && y == math.Trunc(y) {
+			return math.Trunc(x / y), nil
+		}
+		return x / y, nil
+
+	case GeneratorNode:
+		n, ok := functions[node.Value]
+		if !ok {
+			break
+		}
+		if len(node.Args) != n || node.Elem != nil || node.Fields != nil || node.Modifiers != nil {
+			return 0, errorf(node.Col, "%s expects %d arguments", node.Value, n)
+		}
+		if node.Value == "len" || node.Value == "sum" {
+			if node.Args[0].Kind != RefNode {
+				return 0, errorf(node.Args[0].Col, "%s expects a reference to an argument", node.Value)
+			}
+			value, err := refValue(node.Args[0], args)
+			if err != nil {
+				return 0, err
+			}
+			if node.Value == "len" {
+				return float64(value.Len()), nil
+			}
+			return sum(node.Args[0], value)
+		}
+
+		operands := make([]float64, n)
+		for i, arg := range node.Args {
+			x, err := evaluate(arg, args)
+			if err != nil {
+				return 0, err
+			}
+			operands[i] = x
+		}
+		switch node.Value {
+		case "abs":
+			return math.Abs(operands[0]), nil
+		case "min":
+			return math.Min(operands[0], operands[1]), nil
+		case "max":
+			return math.Max(operands[0], operands[1]), nil
+		}
+	}
+
+	return 0, errorf(node.Col, "expected a number")
+}
+
+// truth converts the result of a comparison or a logical operator to a number,
+// that is 1 when true and 0 when false.
+func truth(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// sum computes the sum of the numbers of an array, a tuple, a record or the
+// elements of a map.
+func sum(node *Node, value Value) (float64, error) {
+	switch value.Kind {
+	case ArrayKind, TupleKind, RecordKind, MapKind:
+	default:
+		return 0, errorf(node.Col, "$%s is not a collection", node.Value)
+	}
+
+	total := 0.0
+	for _, elem := range value.Elems {
+		x, ok := elem.Number()
+		if !ok {
+			return 0, errorf(node.Col, "$%s does not only contain numbers", node.Value)
+		}
+		total += x
+	}
+	return total, nil
+}
+
+func refValue(node *Node, args []Value) (Value, error) {
+	i, err := strconv.Atoi(node.Value)
+	if err != nil || i < 1 || i > len(args) {
+		return Value{}, errorf(node.Col, "unknown argument $%s", node.Value)
+	}
+	return args[i-1], nil
+}
+
+func formatNumber(x float64) string {
+	if x == math.Trunc(x) && math.Abs(x) < 1e15 {
+		return strconv.FormatInt(int64(x), 10)
+	}
+	return strconv.FormatFloat(x, 'f', -1, 64)
+}
+
+// resolve replaces the expressions of a syntax tree by their values, given
+// the values of the arguments they may refer to.
+func resolve(node *Node, args []Value) (*Node, error) {
+	return substitute(node, func(expr *Node) (float64, error) {
+		return evaluate(expr, args)
+	})
+}
+
+// substitute replaces the expressions of a syntax tree by the numbers computed
+// by the given function.
+func substitute(node *Node, eval func(expr *Node) (float64, error)) (*Node, error) {
+	if node == nil {
+		return nil, nil
+	}
+	if isExpr(node) {
+		x, err := eval(node)
+		if err != nil {
+			return nil, err
+		}
+		return &Node{Kind: NumberNode, Name: node.Name, Value: formatNumber(x), Weight: node.Weight, Col: node.Col}, nil
+	}
+
+	resolved := *node
+	var err error
+	if resolved.Args, err = substituteAll(node.Args, eval); err != nil {
+		return nil, err
+	}
+	if resolved.Modifiers, err = substituteAll(node.Modifiers, eval); err != nil {
+		return nil, err
+	}
+	if resolved.Elem, err = substitute(node.Elem, eval); err != nil {
+		return nil, err
+	}
+	if resolved.ElemValue, err = substitute(node.ElemValue, eval); err != nil {
+		return nil, err
+	}
+	if node.Fields != nil {
+		resolved.Fields = make([]*Field, len(node.Fields))
+		for i, field := range node.Fields {
+			desc, err := substitute(field.Desc, eval)
+			if err != nil {
+				return nil, err
+			}
+			resolved.Fields[i] = &Field{field.Name, desc, field.Col}
+		}
+	}
+	return &resolved, nil
+}
+
+func substituteAll(nodes []*Node, eval func(expr *Node) (float64, error)) ([]*Node, error) {
+	if nodes == nil {
+		return nil, nil
+	}
+	resolved := make([]*Node, len(nodes))
+	for i, node := range nodes {
+		var err error
+		if resolved[i], err = substitute(node, eval); err != nil {
+			return nil, err
+		}
+	}
+	return resolved, nil
+}
+
+// refs collects the references to other arguments of a syntax tree.
+func refs(node *Node, found map[int]*Node) {
+	if node == nil {
+		return
+	}
+	if node.Kind == RefNode {
+		i, _ := strconv.Atoi(node.Value)
+		found[i-1] = node
+	}
+	for _, arg := range node.Args {
+		refs(arg, found)
+	}
+	for _, modifier := range node.Modifiers {
+		refs(modifier, found)
+	}
+	for _, field := range node.Fields {
+		refs(field.Desc, found)
+	}
+	refs(node.Elem, found)
+	refs(node.ElemValue, found)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Conditions
+
+// Condition is a condition on the values of the arguments of a test, such as
+// $1 < $2 or sum($1) % 2 == 0, written with the same expressions as the
+// arguments of generators, combined with comparison operators and the logical
+// operators &&, || and !.
+type Condition struct {
+	src  string
+	node *Node
+}
+
+// ParseCondition parses a condition on the values of the arguments of a test.
+func ParseCondition(src string) (*Condition, error) {
+	p := &parser{lex: &lexer{src: src}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	if !isExpr(node) && node.Kind != NumberNode {
+		return nil, errorf(node.Col, "expected a condition")
+	}
+	return &Condition{src, node}, nil
+}
+
+// String returns the source of the condition.
+func (c *Condition) String() string {
+	return c.src
+}
+
+// Holds checks whether the condition holds for the given values of arguments,
+// that is whether it evaluates to a nonzero number.
+func (c *Condition) Holds(args []Value) (bool, error) {
+	x, err := evaluate(c.node, args)
+	if err != nil {
+		return false, err
+	}
+	return x != 0, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Dependent arguments
+
+// DependentRandomGenerator generates random values from a descriptor referring
+// to other arguments, such as int(0,len($1)-1), which must be resolved with
+// the values of these arguments.
+type DependentRandomGenerator struct {
+	Node *Node
+	Refs []int
+}
+
+// Resolve builds the generator for the descriptor, given the values of all the
+// arguments, of which at least the referred ones must have been generated.
+func (g DependentRandomGenerator) Resolve(args []Value) (RandomGenerator, error) {
+	node, err := resolve(g.Node, args)
+	if err != nil {
+		return nil, err
+	}
+	return buildGenerator(node)
+}
+
+// Dependencies lists the indices of the arguments the descriptor refers to.
+func (g DependentRandomGenerator) Dependencies() []int {
+	return g.Refs
+}
+
+// Placeholder values of the expressions of dependent descriptors when they are
+// checked, several of them since a single one may not fit, such as 1 in
+// int(10,$1).
+var placeholders = []float64{1, 0, 10, 100, 1000}
+
+// check reports the errors of a dependent descriptor that do not depend on the
+// values it refers to, such as unknown generators or wrong numbers of
+// arguments, so that they are reported when it is built rather than when
+// values are generated. Its expressions are replaced by the same placeholder
+// value, and it is valid if it can be built with any of them.
+func (g DependentRandomGenerator) check() error {
+	var first error
+	for _, x := range placeholders {
+		node, err := substitute(g.Node, func(*Node) (float64, error) { return x, nil })
+		if err == nil {
+			_, err = buildGenerator(node)
+		}
+		if err == nil {
+			return nil
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// Argument generates the values of an argument of a test, with a generator
+// that is resolved with the values of the arguments it depends on, if any.
+type Argument interface {
+	// Resolve gives the generator of the values of the argument, given the
+	// values of all the arguments, of which at least the ones it depends on
+	// must have been generated.
+	Resolve(args []Value) (RandomGenerator, error)
+
+	// Dependencies lists the indices of the arguments it depends on.
+	Dependencies() []int
+}
+
+// IndependentArgument is an argument whose generator does not depend on other
+// arguments.
+type IndependentArgument struct {
+	Generator RandomGenerator
+}
+
+// Resolve gives the generator of the argument.
+func (a IndependentArgument) Resolve(args []Value) (RandomGenerator, error) {
+	return a.Generator, nil
+}
+
+// Dependencies lists no arguments.
+func (a IndependentArgument) Dependencies() []int {
+	return nil
+}
+
+// EvaluationOrder computes an order in which to generate arguments so that
+// the ones referred to by a dependent generator are generated before it.
+func EvaluationOrder(gens []Argument) ([]int, error) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := make([]int, len(gens))
+	order := make([]int, 0, len(gens))
+
+	var visit func(i int, path []int) error
+	visit = func(i int, path []int) error {
+		switch state[i] {
+		case visited:
+			return nil
+		case visiting:
+			start := len(path) - 2
+			for path[start] != i {
+				start--
+			}
+			cycle := make([]string, 0, len(path)-start)
+			for _, j := range path[start:] {
+				cycle = append(cycle, "$"+strconv.Itoa(j+1))
+			}
+			return fmt.Errorf("cyclic references between arguments %s", strings.Join(cycle, " -> "))
+		}
+
+		state[i] = visiting
+		for _, j := range gens[i].Dependencies() {
+			if j < 0 || j >= len(gens) {
+				return fmt.Errorf("argument $%d refers to unknown argument $%d", i+1, j+1)
+			}
+			if err := visit(j, append(path, j)); err != nil {
+				return err
+			}
+		}
+		state[i] = visited
+		order = append(order, i)
+		return nil
+	}
+
+	for i := range gens {
+		if err := visit(i, []int{i}); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
+func newDependentGenerator(node *Node) (DependentRandomGenerator, bool) {
+	found := make(map[int]*Node)
+	refs(node, found)
+	if len(found) == 0 {
+		return DependentRandomGenerator{}, false
+	}
+
+	indices := make([]int, 0, len(found))
+	for i := range found {
+		indices = append(indices, i)
+	}
+	sort.Ints(indices)
+	return DependentRandomGenerator{node, indices}, true
+}
@@ -0,0 +1,238 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestDependentArguments(t *testing.T) {
+	gens, err := BuildGenerators("array(0,5)[int(0,9)]", "int(0,len($1)-1)", "str(len($1),len($1)*2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := EvaluationOrder(gens)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	resolved := 0
+	for k := 0; k < samples; k++ {
+		args := make([]Value, len(gens))
+		var err error
+		for _, i := range order {
+			var g RandomGenerator
+			if g, err = gens[i].Resolve(args); err != nil {
+				break
+			}
+			args[i] = GenerateValue(g, r)
+		}
+		if n := args[0].Len(); err != nil {
+			// Only an empty array leaves no valid index.
+			if n != 0 {
+				t.Fatalf("%v: %s", args, err)
+			}
+			continue
+		}
+		resolved++
+		if x, _ := args[1].Number(); int(x) >= args[0].Len() {
+			t.Fatalf("index %v out of %v", args[1], args[0])
+		}
+		if n := args[2].Len(); n < args[0].Len() || n > 2*args[0].Len() {
+			t.Fatalf("string %v for array %v", args[2], args[0])
+		}
+	}
+	if resolved == 0 {
+		t.Fatal("no arguments could be resolved")
+	}
+}
+
+func TestResolveWithoutArguments(t *testing.T) {
+	gens, err := BuildGenerators("int(0,$2)", "int(0,9)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if deps := gens[0].Dependencies(); len(deps) != 1 || deps[0] != 1 || gens[1].Dependencies() != nil {
+		t.Fatalf("unexpected dependencies %v and %v", deps, gens[1].Dependencies())
+	}
+	if _, err := gens[0].Resolve(nil); err == nil {
+		t.Fatal("expected an error when resolving without arguments")
+	}
+	if _, err := gens[0].Resolve([]Value{{}, IntValue(-1)}); err == nil {
+		t.Fatal("expected an error for invalid resolved bounds")
+	}
+}
+
+func TestEvaluationOrderErrors(t *testing.T) {
+	checkErrors(t, map[string]string{
+		"int(0,$1)": "cyclic references between arguments $1 -> $1",
+		"int(0,$3)": "refers to unknown argument $3",
+	})
+	if _, err := BuildGenerators("int(0,$2)", "int(0,$1)"); err == nil {
+		t.Error("expected an error for cyclic references")
+	}
+}
+
+func TestDependentDescriptorErrors(t *testing.T) {
+	tests := map[string]string{
+		"intt(0,len($1)-1)":           "unknown generator 'intt'",
+		"int(0,len($1)-1,2)":          "int expects 2 arguments",
+		"str(1,len($1),len($1))":      "expected an alphabet string",
+		"int(0,len($1))|sorted":       "unknown modifier 'sorted' for int",
+		"array(0,len($1))":            "array expects an element descriptor",
+		"array(0,len($1))[intt(0,9)]": "unknown generator 'intt'",
+	}
+	for desc, msg := range tests {
+		if _, err := BuildGenerators("array(1,3)[int(0,9)]", desc); err == nil {
+			t.Errorf("%s: expected an error", desc)
+		} else if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: got error %q, expected %q", desc, err, msg)
+		}
+	}
+
+	// Descriptors that can only be built with some values are accepted.
+	for _, desc := range []string{"int(10,$2)", "int($2,$2*2)", "float(0,1)|precision($2)", "int(0,$2-1)"} {
+		if _, err := BuildGenerators("array(1,3)[int(0,9)]", "int(0,100)", desc); err != nil {
+			t.Errorf("%s: %s", desc, err)
+		}
+	}
+}
+
+func TestExpressions(t *testing.T) {
+	args := []Value{ArrayValue([]Value{IntValue(1), IntValue(2), IntValue(4)}), IntValue(3), StringValue("abc")}
+	tests := map[string]float64{
+		"len($1)-1":         2,
+		"-$2*2+1":           -5,
+		"7/2":               3,
+		"7%$2":              1,
+		"7.5/2":             3.75,
+		"max(len($3),$2*2)": 6,
+		"abs(min(-4,2))":    4,
+	}
+	for src, want := range tests {
+		node, err := Parse("int(0," + src + ")")
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		if got, err := evaluate(node.Args[1], args); err != nil || got != want {
+			t.Errorf("%s: got %v (%v), expected %v", src, got, err, want)
+		}
+	}
+
+	for _, src := range []string{"1/($2-3)", "len(2)", "$2+$3", "$4"} {
+		node, err := Parse("int(0," + src + ")")
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		if _, err := evaluate(node.Args[1], args); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+}
+
+func TestDependentElements(t *testing.T) {
+	gens, err := BuildGenerators("array(1,4)[int(0,$2)]|sorted", "int(0,5)", "map($2,$2)[int(1,$2+1)->bool]")
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := EvaluationOrder(gens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if order[0] != 1 {
+		t.Fatalf("got order %v, expected $2 first", order)
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for k := 0; k < samples; k++ {
+		args := make([]Value, len(gens))
+		for _, i := range order {
+			g, err := gens[i].Resolve(args)
+			if err != nil {
+				t.Fatalf("%v: %s", args, err)
+			}
+			args[i] = GenerateValue(g, r)
+		}
+		max, _ := args[1].Number()
+		for _, elem := range args[0].Elems {
+			if x, _ := elem.Number(); x > max {
+				t.Fatalf("element %v of %v greater than %v", elem, args[0], args[1])
+			}
+		}
+		if args[2].Len() != int(max) {
+			t.Fatalf("map %v does not have %v keys", args[2], args[1])
+		}
+	}
+}
+
+func TestConditions(t *testing.T) {
+	args := []Value{ArrayValue([]Value{IntValue(1), IntValue(2), IntValue(4)}), IntValue(0), FloatValue(2.5, 1)}
+	tests := map[string]bool{
+		"$2 < $3":                      true,
+		"sum($1) % 2 == 1":             true,
+		"sum($1) == 7 && len($1) >= 3": true,
+		"$2 != 0 && 10 / $2 > 1":       false,
+		"$2 == 0 || 10 / $2 > 1":       true,
+		"!($3 > 2)":                    false,
+		"$3 * 2 == 5":                  true,
+		"1":                            true,
+		"max($2, -1) <= -1 || $2 >= 0": true,
+	}
+	for src, want := range tests {
+		where, err := ParseCondition(src)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+			continue
+		}
+		if got, err := where.Holds(args); err != nil || got != want {
+			t.Errorf("%s: got %v (%v), expected %v", src, got, err, want)
+		}
+		if where.String() != src {
+			t.Errorf("%s: got source %s", src, where)
+		}
+	}
+
+	for _, src := range []string{"10 / $2 > 1", "sum($3) > 0", "$5 == 1"} {
+		where, err := ParseCondition(src)
+		if err != nil {
+			t.Errorf("%s: %s", src, err)
+		} else if _, err := where.Holds(args); err == nil {
+			t.Errorf("%s: expected an error", src)
+		}
+	}
+
+	errors := map[string]string{
+		"":        "unexpected end of descriptor at column 1",
+		"$1 <":    "unexpected end of descriptor at column 5",
+		"$1 < 2)": "unexpected ')' at column 7",
+		"bool":    "expected a condition at column 1",
+		"$1 = 2":  "unexpected '=' at column 4",
+	}
+	for src, msg := range errors {
+		if _, err := ParseCondition(src); err == nil || err.Error() != msg {
+			t.Errorf("%q: got error %v, expected %q", src, err, msg)
+		}
+	}
+}
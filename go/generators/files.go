@@ -0,0 +1,68 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+)
+
+// Dir is the directory against which the relative paths of the files named by
+// descriptors, such as expr.bnf in grammar("expr.bnf",10), are resolved.
+var Dir = "."
+
+type fileKey struct {
+	kind string
+	path string
+}
+
+var (
+	filesMu sync.Mutex
+	files   = make(map[fileKey]interface{})
+)
+
+// loadFile reads a file named by a descriptor and parses its content as the
+// given kind of file, such as a grammar. Parsed contents are cached, so that a
+// file is read only once even if generators are built again, such as dependent
+// ones.
+func loadFile(kind string, path string, parse func(data []byte) (interface{}, error)) (interface{}, error) {
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(Dir, path)
+	}
+
+	filesMu.Lock()
+	defer filesMu.Unlock()
+	key := fileKey{kind, path}
+	if content, ok := files[key]; ok {
+		return content, nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	content, err := parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %s", filepath.Base(path), err)
+	}
+	files[key] = content
+	return content, nil
+}
@@ -21,24 +21,28 @@ package generators
 
 import (
 	"fmt"
+	"math"
+	"math/big"
 	"math/rand"
-	"regexp"
+	"sort"
 	"strconv"
 	"strings"
 )
 
+// RandomGenerator generates random values, drawing from the given source so
+// that a run can be reproduced from its seed.
 type RandomGenerator interface {
-	Generate() string
+	Generate(r *rand.Rand) string
 }
 
 type ArrayGenerator struct {
 	Generators []RandomGenerator
 }
 
-func (g ArrayGenerator) Generate() string {
+func (g ArrayGenerator) Generate(r *rand.Rand) string {
 	result := make([]string, len(g.Generators))
 	for i, generator := range g.Generators {
-		result[i] = generator.Generate()
+		result[i] = generator.Generate(r)
 	}
 	fmt.Println(result)
 	return ""
@@ -48,17 +52,44 @@ func (g ArrayGenerator) Generate() string {
 // int
 
 type IntRandomGenerator struct {
-	Min int
-	Max int
+	Min      int
+	Max      int
+	EdgeBias float64
 }
 
-// Generates a random integer number comprised between two bounds.
-func (g IntRandomGenerator) Generate() string {
-	return fmt.Sprintf("%d", randint(g.Min, g.Max))
+// Generates a random integer number comprised between two bounds, which is an
+// edge value (min, max, 0, -1 or 1) with a probability given by the edge bias.
+func (g IntRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	if g.EdgeBias > 0 && r.Float64() < g.EdgeBias {
+		edges := make([]int, 0, 5)
+		for _, value := range []int{g.Min, g.Max, 0, -1, 1} {
+			if g.Min <= value && value <= g.Max {
+				edges = append(edges, value)
+			}
+		}
+		return IntValue(edges[randint(r, 0, len(edges)-1)])
+	}
+	return IntValue(randint(r, g.Min, g.Max))
+}
+
+func (g IntRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
 }
 
-func randint(min int, max int) int {
-	return min + (int(rand.Int63()) % (max - min + 1))
+// randint draws an integer comprised between two bounds, which can span the
+// whole range of integers. Draws above the largest multiple of the size of the
+// range are rejected, so that all the integers are equally likely.
+func randint(r *rand.Rand, min int, max int) int {
+	n := uint64(max-min) + 1
+	if n == 0 {
+		return int(r.Uint64())
+	}
+	threshold := -n % n
+	v := r.Uint64()
+	for v < threshold {
+		v = r.Uint64()
+	}
+	return min + int(v%n)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -68,24 +99,50 @@ type BoolRandomGenerator struct {
 }
 
 // Generates a random boolean value.
-func (g BoolRandomGenerator) Generate() string {
-	if rand.Intn(2) == 0 {
-		return "true"
-	}
-	return "false"
+func (g BoolRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return BoolValue(r.Intn(2) == 0)
+}
+
+func (g BoolRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // float
 
 type FloatRandomGenerator struct {
-	Min float64
-	Max float64
+	Min       float64
+	Max       float64
+	EdgeBias  float64
+	Precision int // decimals, six if negative
+}
+
+// Generates a random floating-point number comprised between two bounds, which is
+// an edge value (min, max, 0, -1 or 1) with a probability given by the edge bias.
+func (g FloatRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	if g.EdgeBias > 0 && r.Float64() < g.EdgeBias {
+		edges := make([]float64, 0, 5)
+		for _, value := range []float64{g.Min, g.Max, 0, -1, 1} {
+			if g.Min <= value && value <= g.Max {
+				edges = append(edges, value)
+			}
+		}
+		return FloatValue(edges[randint(r, 0, len(edges)-1)], g.Precision)
+	}
+	return FloatValue(g.Min+(r.Float64()*(g.Max-g.Min)), g.Precision)
 }
 
-// Generates a random floating-point number comprised between two bounds.
-func (g FloatRandomGenerator) Generate() string {
-	return fmt.Sprintf("%f", g.Min+(rand.Float64()*(g.Max-g.Min)))
+func (g FloatRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// formatFloat formats a floating-point number with the given number of
+// decimals, or with six decimals when it is negative.
+func formatFloat(x float64, precision int) string {
+	if precision < 0 {
+		return fmt.Sprintf("%f", x)
+	}
+	return strconv.FormatFloat(x, 'f', precision, 64)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -94,115 +151,737 @@ func (g FloatRandomGenerator) Generate() string {
 type StringRandomGenerator struct {
 	MinLength int
 	MaxLength int
+	Alphabet  []rune
+}
+
+// Character classes that can be used as alphabets, given as pairs of inclusive bounds.
+var characterClasses = map[string][]rune{
+	"lower":     {'a', 'z'},
+	"upper":     {'A', 'Z'},
+	"letters":   {'a', 'z', 'A', 'Z'},
+	"digits":    {'0', '9'},
+	"alnum":     {'a', 'z', 'A', 'Z', '0', '9'},
+	"punct":     {'!', '/', ':', '@', '[', '`', '{', '~'},
+	"printable": {' ', '~'},
+	"unicode": {
+		' ', '~', // ASCII
+		'¡', '¬', '®', 'ÿ', // Latin-1 Supplement
+		'Ā', 'ſ', // Latin Extended-A
+		'Α', 'Ρ', 'Σ', 'ω', // Greek
+		'А', 'я', // Cyrillic
+		'一', '丿', // CJK Unified Ideographs (sample)
+		'😀', '🙏', // Emoticons
+	},
 }
 
-// Generates a random string with a random number of characters comprised between two bounds.
-func (g StringRandomGenerator) Generate() string {
-	alphabet := "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	length := randint(g.MinLength, g.MaxLength)
+// Generates a random string with a random number of characters comprised between two bounds,
+// drawn from an alphabet given as pairs of inclusive bounds (letters and digits by default).
+func (g StringRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	alphabet := g.Alphabet
+	if alphabet == nil {
+		alphabet = characterClasses["alnum"]
+	}
+	length := randint(r, g.MinLength, g.MaxLength)
 
 	var sb strings.Builder
 	for i := 0; i < length; i++ {
-		sb.WriteString(string(alphabet[randint(0, len(alphabet)-1)]))
+		sb.WriteRune(randrune(r, alphabet))
 	}
-	return sb.String()
+	return StringValue(sb.String())
+}
+
+func (g StringRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // enum
 
 type EnumRandomGenerator struct {
-	Values []string
+	Values  []string
+	Weights []float64
+}
+
+// Generates a random value from an enumeration, uniformly or according to the
+// weights of the values, if any. Values that are numbers are typed as such.
+func (g EnumRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return literalValue(g.Generate(r))
 }
 
-// Generates a random value from an enumeration.
-func (g EnumRandomGenerator) Generate() string {
-	return g.Values[randint(0, len(g.Values)-1)]
+func (g EnumRandomGenerator) Generate(r *rand.Rand) string {
+	return g.Values[choose(r, len(g.Values), g.Weights)]
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // array
 
 type ArrayRandomGenerator struct {
-	MinLength int
-	MaxLength int
-	Desc      string
+	MinLength  int
+	MaxLength  int
+	Generator  RandomGenerator
+	Sorted     bool
+	Unique     bool
+	Descending bool
 }
 
-// Generates a random value from an enumeration.
-func (g ArrayRandomGenerator) Generate() string {
-	length := randint(g.MinLength, g.MaxLength)
-	generator := buildGenerator(g.Desc)
+// Generates a random array whose elements are produced by another generator,
+// possibly distinct from each other and sorted in ascending or descending order.
+func (g ArrayRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	length := randint(r, g.MinLength, g.MaxLength)
+
+	var data []Value
+	if g.Unique {
+		data = g.generateUnique(r, length)
+	} else {
+		data = make([]Value, length)
+		for i := 0; i < length; i++ {
+			data[i] = GenerateValue(g.Generator, r)
+		}
+	}
 
-	data := make([]string, length)
-	for i := 0; i < length; i++ {
-		data[i] = generator.Generate()
+	if g.Sorted || g.Descending {
+		g.sort(data)
 	}
-	return "[" + strings.Join(data, " ") + "]"
+	return ArrayValue(data)
+}
+
+func (g ArrayRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// sort sorts elements in ascending order, or in descending order if required.
+func (g ArrayRandomGenerator) sort(data []Value) {
+	sort.SliceStable(data, func(i, j int) bool {
+		if g.Descending {
+			return lessValues(data[j], data[i])
+		}
+		return lessValues(data[i], data[j])
+	})
+}
+
+// generateUnique generates distinct elements, directly sampled from the range
+// for integers and drawn until enough distinct ones are found otherwise.
+func (g ArrayRandomGenerator) generateUnique(r *rand.Rand, length int) []Value {
+	if n, ok := cardinality(g.Generator); ok && n < length {
+		length = n
+	}
+
+	if generator, ok := g.Generator.(IntRandomGenerator); ok && generator.Max-generator.Min+1 > 0 {
+		// Floyd's algorithm to sample distinct integers without rejection.
+		n := generator.Max - generator.Min + 1
+		chosen := make(map[int]bool, length)
+		data := make([]Value, 0, length)
+		for j := n - length; j < n; j++ {
+			t := randint(r, 0, j)
+			if chosen[t] {
+				t = j
+			}
+			chosen[t] = true
+			data = append(data, IntValue(generator.Min+t))
+		}
+		r.Shuffle(len(data), func(i, j int) {
+			data[i], data[j] = data[j], data[i]
+		})
+		return data
+	}
+
+	return drawDistinct(r, g.Generator, length)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// permutation
+
+type PermutationRandomGenerator struct {
+	N int
+}
+
+// Generates a random permutation of the integers from 1 to n.
+func (g PermutationRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	data := make([]Value, g.N)
+	for i, value := range r.Perm(g.N) {
+		data[i] = IntValue(value + 1)
+	}
+	return ArrayValue(data)
+}
+
+func (g PermutationRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// map
+
+type MapRandomGenerator struct {
+	MinLength      int
+	MaxLength      int
+	KeyGenerator   RandomGenerator
+	ValueGenerator RandomGenerator
+}
+
+// Generates a random map whose distinct keys and values are produced by other generators.
+func (g MapRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	length := randint(r, g.MinLength, g.MaxLength)
+	if n, ok := cardinality(g.KeyGenerator); ok && n < length {
+		length = n
+	}
+
+	keys := drawDistinct(r, g.KeyGenerator, length)
+	data := make([]Value, len(keys))
+	for i := range keys {
+		data[i] = GenerateValue(g.ValueGenerator, r)
+	}
+	return MapValue(keys, data)
+}
+
+func (g MapRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// tuple
+
+type TupleRandomGenerator struct {
+	Generators []RandomGenerator
+}
+
+// Generates a random tuple whose components are produced by other generators.
+func (g TupleRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	data := make([]Value, len(g.Generators))
+	for i, generator := range g.Generators {
+		data[i] = GenerateValue(generator, r)
+	}
+	return TupleValue(data)
+}
+
+func (g TupleRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// record
+
+type RecordRandomGenerator struct {
+	Names      []string
+	Generators []RandomGenerator
+}
+
+// Generates a random record whose named fields are produced by other generators.
+func (g RecordRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	data := make([]Value, len(g.Generators))
+	for i, generator := range g.Generators {
+		data[i] = GenerateValue(generator, r)
+	}
+	return RecordValue(g.Names, data)
+}
+
+func (g RecordRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
 }
 
 ////////////////////////////////////////////////////////////////////////////////
 // Utility functions
 
-const (
-	intPattern   = `0|-{0,1}[1-9][0-9]*`
-	floatPattern = `0|-{0,1}[1-9][0-9]*(?:\.[0-9]*[1-9]){0,1}`
-)
+// Maximum number of draws per value when looking for distinct values.
+const maxAttempts = 100
+
+// Maximum cardinality considered as finite when counting distinct values.
+const maxCardinality = 1 << 30
+
+// cardinality computes the number of distinct values a generator can produce,
+// capped to maxCardinality, if it is known. Bounds that cannot be counted
+// exactly, as for floating-point numbers, are counted from below.
+func cardinality(g RandomGenerator) (int, bool) {
+	switch g := g.(type) {
+	case IntRandomGenerator:
+		if n := g.Max - g.Min + 1; n > 0 && n <= maxCardinality {
+			return n, true
+		}
+		return maxCardinality, true
+	case BigIntRandomGenerator:
+		n := new(big.Int).Sub(g.Max, g.Min)
+		if n.Cmp(big.NewInt(maxCardinality)) >= 0 {
+			return maxCardinality, true
+		}
+		return int(n.Int64()) + 1, true
+	case FloatRandomGenerator:
+		// Every multiple of the last decimal within the bounds can be produced.
+		scale := math.Pow10(6)
+		if g.Precision >= 0 {
+			scale = math.Pow10(g.Precision)
+		}
+		n := math.Floor(g.Max*scale) - math.Ceil(g.Min*scale) + 1
+		if n >= maxCardinality {
+			return maxCardinality, true
+		}
+		if n < 1 {
+			return 1, true
+		}
+		return int(n), true
+	case BoolRandomGenerator:
+		return 2, true
+	case EnumRandomGenerator:
+		values := make(map[string]bool, len(g.Values))
+		for i, value := range g.Values {
+			if g.Weights == nil || g.Weights[i] > 0 {
+				values[value] = true
+			}
+		}
+		return len(values), true
+	case PickRandomGenerator:
+		return len(g.Corpus.distinctLines()), true
+	case StringRandomGenerator:
+		alphabet := g.Alphabet
+		if alphabet == nil {
+			alphabet = characterClasses["alnum"]
+		}
+		k := 0
+		for i := 0; i < len(alphabet); i += 2 {
+			k += int(alphabet[i+1]-alphabet[i]) + 1
+		}
+		n, m := 0, 1
+		for length := 0; length <= g.MaxLength; length++ {
+			if length >= g.MinLength {
+				n += m
+			}
+			if n >= maxCardinality || (length < g.MaxLength && m > maxCardinality/k) {
+				return maxCardinality, true
+			}
+			m *= k
+		}
+		return n, true
+	case TupleRandomGenerator:
+		return product(g.Generators)
+	case RecordRandomGenerator:
+		return product(g.Generators)
+	case NullRandomGenerator:
+		return 1, true
+	case OptionalRandomGenerator:
+		if g.P == 1 {
+			return 1, true
+		}
+		n, ok := cardinality(g.Generator)
+		if ok && g.P > 0 && n < maxCardinality {
+			n++
+		}
+		return n, ok
+	}
+	return 0, false
+}
 
-func buildGenerator(desc string) RandomGenerator {
-	var regex *regexp.Regexp
+// product computes the number of distinct combinations of values of several
+// generators, capped to maxCardinality, if it is known.
+func product(gens []RandomGenerator) (int, bool) {
+	n := 1
+	for _, generator := range gens {
+		m, ok := cardinality(generator)
+		if !ok {
+			return 0, false
+		}
+		if n > maxCardinality/m {
+			n = maxCardinality
+		} else {
+			n *= m
+		}
+	}
+	return n, true
+}
 
-	// int(min,max)
-	regex, _ = regexp.Compile(fmt.Sprintf(`^int\((%[1]s),(%[1]s)\)$`, intPattern))
-	if matches := regex.FindStringSubmatch(desc); matches != nil {
-		min, _ := strconv.Atoi(matches[1])
-		max, _ := strconv.Atoi(matches[2])
-		return IntRandomGenerator{min, max}
+// drawDistinct draws n distinct values from a generator. When the draws keep
+// repeating values, the missing ones are taken from the enumeration of all the
+// values of the generator or, failing that, drawn until found provided that the
+// generator is known to produce enough distinct values. Otherwise, fewer values
+// may be returned.
+func drawDistinct(r *rand.Rand, g RandomGenerator, n int) []Value {
+	seen := make(map[string]bool, n)
+	data := make([]Value, 0, n)
+	add := func(value Value) {
+		if key := value.String(); !seen[key] {
+			seen[key] = true
+			data = append(data, value)
+		}
 	}
 
-	// bool
-	if desc == "bool" {
-		return BoolRandomGenerator{}
+	m, known := cardinality(g)
+	known = known && m >= n
+	for attempts := 0; len(data) < n; attempts++ {
+		if attempts == maxAttempts*n {
+			if values, ok := Enumerate(g, maxAttempts*n); ok {
+				r.Shuffle(len(values), func(i, j int) {
+					values[i], values[j] = values[j], values[i]
+				})
+				for _, value := range values {
+					if len(data) == n {
+						break
+					}
+					add(value)
+				}
+				break
+			}
+			if !known {
+				break
+			}
+		}
+		add(GenerateValue(g, r))
 	}
+	return data
+}
 
-	// float(min,max)
-	regex, _ = regexp.Compile(fmt.Sprintf(`^float\((%[1]s),(%[1]s)\)$`, floatPattern))
-	if matches := regex.FindStringSubmatch(desc); matches != nil {
-		min, _ := strconv.ParseFloat(matches[1], 64)
-		max, _ := strconv.ParseFloat(matches[2], 64)
-		return FloatRandomGenerator{min, max}
+////////////////////////////////////////////////////////////////////////////////
+// Factories of the built-in generators
+
+// int(min,max)
+func newIntGenerator(p *Params) (RandomGenerator, error) {
+	var min, max int
+	if err := p.Scan(&min, &max); err != nil {
+		return nil, err
+	}
+	if min > max {
+		return nil, p.Errorf("invalid bounds, %d is greater than %d", min, max)
+	}
+	bias, err := edgeBias(p)
+	if err != nil {
+		return nil, err
+	}
+	return IntRandomGenerator{min, max, bias}, nil
+}
+
+// bool
+func newBoolGenerator(p *Params) (RandomGenerator, error) {
+	if err := p.Scan(); err != nil {
+		return nil, err
+	}
+	return BoolRandomGenerator{}, nil
+}
+
+// float(min,max)
+func newFloatGenerator(p *Params) (RandomGenerator, error) {
+	var min, max float64
+	if err := p.Scan(&min, &max); err != nil {
+		return nil, err
+	}
+	if min > max {
+		return nil, p.Errorf("invalid bounds, %g is greater than %g", min, max)
+	}
+	bias, err := edgeBias(p)
+	if err != nil {
+		return nil, err
+	}
+	decimals, err := precision(p)
+	if err != nil {
+		return nil, err
+	}
+	return FloatRandomGenerator{min, max, bias, decimals}, nil
+}
+
+// str(minlen,maxlen[,alphabet])
+func newStringGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() < 2 || p.Len() > 3 {
+		return nil, p.Errorf("str expects 2 or 3 arguments")
+	}
+	min, max, err := lengthBounds(p)
+	if err != nil {
+		return nil, err
+	}
+
+	var alphabet []rune
+	if p.Len() == 3 {
+		arg := p.Arg(2)
+		if value, err := arg.Str(); err == nil {
+			if value == "" {
+				return nil, arg.Errorf("alphabet cannot be empty")
+			}
+			for _, c := range value {
+				alphabet = append(alphabet, c, c)
+			}
+		} else if class, err := arg.Ident(); err == nil && characterClasses[class] != nil {
+			alphabet = characterClasses[class]
+		} else {
+			return nil, arg.Errorf("expected an alphabet string or a character class")
+		}
+	}
+	return StringRandomGenerator{min, max, alphabet}, nil
+}
+
+// enum(value[:weight],...)
+func newEnumGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() == 0 {
+		return nil, p.Errorf("enum expects at least one value")
+	}
+
+	values := make([]string, p.Len())
+	var weights []float64
+	total := 0.0
+	for i := range values {
+		arg := p.Arg(i)
+		value, err := arg.Value()
+		if err != nil {
+			return nil, err
+		}
+		values[i] = value
+
+		weight, ok, err := arg.Weight()
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			if weights == nil {
+				weights = make([]float64, len(values))
+				for j := range weights {
+					weights[j] = 1
+				}
+			}
+			weights[i] = weight
+		}
+	}
+	for _, weight := range weights {
+		total += weight
+	}
+	if weights != nil && total == 0 {
+		return nil, p.Errorf("enum expects at least one positive weight")
+	}
+	return EnumRandomGenerator{values, weights}, nil
+}
+
+// array(minlen,maxlen)[desc]
+func newArrayGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() != 2 {
+		return nil, p.Errorf("%s expects 2 arguments", p.Name())
+	}
+	min, max, err := lengthBounds(p)
+	if err != nil {
+		return nil, err
+	}
+	generator, err := p.Elem()
+	if err != nil {
+		return nil, err
+	}
+
+	array := ArrayRandomGenerator{
+		MinLength:  min,
+		MaxLength:  max,
+		Generator:  generator,
+		Sorted:     p.Modifier("sorted") != nil,
+		Unique:     p.Modifier("unique") != nil,
+		Descending: p.Modifier("desc") != nil,
+	}
+	if array.Unique && max > 1 {
+		n, ok := cardinality(generator)
+		if !ok {
+			return nil, p.Errorf("element descriptor cannot guarantee distinct values")
+		}
+		if n < min {
+			return nil, p.Errorf("element descriptor has only %d distinct values, %d required", n, min)
+		}
+	}
+	return array, nil
+}
+
+// permutation(n)
+func newPermutationGenerator(p *Params) (RandomGenerator, error) {
+	var n int
+	if err := p.Scan(&n); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, p.Arg(0).Errorf("length cannot be negative")
+	}
+	return PermutationRandomGenerator{n}, nil
+}
+
+// map(minlen,maxlen)[keydesc->valuedesc]
+func newMapGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() != 2 {
+		return nil, p.Errorf("%s expects 2 arguments", p.Name())
+	}
+	min, max, err := lengthBounds(p)
+	if err != nil {
+		return nil, err
+	}
+	keyGenerator, err := p.Elem()
+	if err != nil {
+		return nil, err
 	}
+	valueGenerator, err := p.ElemValue()
+	if err != nil {
+		return nil, err
+	}
+	if max > 1 {
+		n, ok := cardinality(keyGenerator)
+		if !ok {
+			return nil, p.Errorf("key descriptor cannot guarantee distinct keys")
+		}
+		if n < min {
+			return nil, p.Errorf("key descriptor has only %d distinct values, %d required", n, min)
+		}
+	}
+	return MapRandomGenerator{min, max, keyGenerator, valueGenerator}, nil
+}
 
-	// str(minlen,maxlen)
-	regex, _ = regexp.Compile(fmt.Sprintf(`^str\((%[1]s),(%[1]s)\)$`, intPattern))
-	if matches := regex.FindStringSubmatch(desc); matches != nil {
-		minLength, _ := strconv.Atoi(matches[1])
-		maxLength, _ := strconv.Atoi(matches[2])
-		return StringRandomGenerator{minLength, maxLength}
+// tuple(desc,...)
+func newTupleGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() == 0 {
+		return nil, p.Errorf("tuple expects at least one component")
 	}
+	generators := make([]RandomGenerator, p.Len())
+	for i := range generators {
+		generator, err := p.Arg(i).Generator()
+		if err != nil {
+			return nil, err
+		}
+		generators[i] = generator
+	}
+	return TupleRandomGenerator{generators}, nil
+}
 
-	// enum(list)
-	regex, _ = regexp.Compile(`^enum\((.+)\)$`)
-	if matches := regex.FindStringSubmatch(desc); matches != nil {
-		return EnumRandomGenerator{strings.Split(matches[1], ",")}
+// record{name:desc,...}
+func newRecordGenerator(p *Params) (RandomGenerator, error) {
+	names, generators, err := p.Fields()
+	if err != nil {
+		return nil, err
 	}
+	return RecordRandomGenerator{names, generators}, nil
+}
 
-	// array(minlen,maxlen)[desc]
-	regex, _ = regexp.Compile(fmt.Sprintf(`^array\((%[1]s),(%[1]s)\)\[(.+)\]$`, intPattern))
-	if matches := regex.FindStringSubmatch(desc); matches != nil {
-		minLength, _ := strconv.Atoi(matches[1])
-		maxLength, _ := strconv.Atoi(matches[2])
-		return ArrayRandomGenerator{minLength, maxLength, matches[3]}
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
 	}
+	return false
+}
+
+// Default probability to generate an edge value with the edges modifier.
+const defaultEdgeBias = 0.2
 
-	return nil
+// edgeBias reads the probability to generate an edge value from the edges
+// modifier of a numeric generator, such as int(0,100)|edges(0.3).
+func edgeBias(p *Params) (float64, error) {
+	return probabilityModifier(p, "edges", defaultEdgeBias)
 }
 
-func BuildGenerators(descs ...string) []RandomGenerator {
-	generators := make([]RandomGenerator, len(descs))
+// probabilityModifier reads a probability from a modifier, which is zero when
+// it is absent and the given default when it has no argument.
+func probabilityModifier(p *Params, name string, def float64) (float64, error) {
+	modifier := p.Modifier(name)
+	if modifier == nil {
+		return 0, nil
+	}
+	if modifier.Len() == 0 {
+		return def, nil
+	}
+
+	var bias float64
+	if err := modifier.Scan(&bias); err != nil {
+		return 0, err
+	}
+	if bias < 0 || bias > 1 {
+		return 0, modifier.Arg(0).Errorf("probability must be comprised between 0 and 1")
+	}
+	return bias, nil
+}
+
+// precision reads the number of decimals from the precision modifier of a
+// floating-point generator, such as float(0,1)|precision(2), which is -1 for
+// the default of six decimals when there is no such modifier.
+func precision(p *Params) (int, error) {
+	modifier := p.Modifier("precision")
+	if modifier == nil {
+		return -1, nil
+	}
+
+	var decimals int
+	if err := modifier.Scan(&decimals); err != nil {
+		return 0, err
+	}
+	if decimals < 0 {
+		return 0, modifier.Arg(0).Errorf("precision cannot be negative")
+	}
+	return decimals, nil
+}
+
+// clamp reads the bounds from the clamp modifier of a generator sampling from a
+// statistical distribution, such as normal(0,1)|clamp(-3,3).
+func clamp(p *Params) (*Clamp, error) {
+	modifier := p.Modifier("clamp")
+	if modifier == nil {
+		return nil, nil
+	}
+
+	var min, max float64
+	if err := modifier.Scan(&min, &max); err != nil {
+		return nil, err
+	}
+	if min > max {
+		return nil, modifier.Errorf("invalid bounds, %g is greater than %g", min, max)
+	}
+	return &Clamp{min, max}, nil
+}
+
+// intClamp reads the clamp modifier of a distribution of integers, whose
+// bounds must contain at least one integer.
+func intClamp(p *Params) (*Clamp, error) {
+	bounds, err := clamp(p)
+	if err != nil || bounds == nil {
+		return bounds, err
+	}
+	if math.Ceil(bounds.Min) > math.Floor(bounds.Max) {
+		return nil, p.Modifier("clamp").Errorf("no integer between %g and %g", bounds.Min, bounds.Max)
+	}
+	return bounds, nil
+}
+
+// lengthBounds reads the minimum and maximum lengths from the first two
+// arguments of a descriptor, such as str(1,10).
+func lengthBounds(p *Params) (int, int, error) {
+	min, err := p.Arg(0).Int()
+	if err != nil {
+		return 0, 0, err
+	}
+	max, err := p.Arg(1).Int()
+	if err != nil {
+		return 0, 0, err
+	}
+	if min < 0 {
+		return 0, 0, p.Arg(0).Errorf("length cannot be negative")
+	}
+	if min > max {
+		return 0, 0, p.Errorf("invalid bounds, %d is greater than %d", min, max)
+	}
+	return min, max, nil
+}
+
+// BuildGenerators builds the generators corresponding to the given descriptors.
+// Descriptors referring to other arguments, such as $1 in int(0,len($1)-1),
+// lead to dependent generators, provided that there is no cyclic reference.
+// Their errors that do not depend on the values they refer to, such as unknown
+// generators, are reported here rather than when they are resolved.
+func BuildGenerators(descs ...string) ([]Argument, error) {
+	generators := make([]Argument, len(descs))
 	for i, desc := range descs {
-		generators[i] = buildGenerator(desc)
+		node, err := Parse(desc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptor %q: %s", desc, err)
+		}
+		if generator, ok := newDependentGenerator(node); ok {
+			if err := generator.check(); err != nil {
+				return nil, fmt.Errorf("invalid descriptor %q: %s", desc, err)
+			}
+			generators[i] = generator
+			continue
+		}
+		var generator RandomGenerator
+		if node, err = resolve(node, nil); err == nil {
+			generator, err = buildGenerator(node)
+		}
+		generators[i] = IndependentArgument{generator}
+		if err != nil {
+			return nil, fmt.Errorf("invalid descriptor %q: %s", desc, err)
+		}
 	}
 
-	return generators
+	if _, err := EvaluationOrder(generators); err != nil {
+		return nil, err
+	}
+	return generators, nil
 }
@@ -0,0 +1,324 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// Number of values drawn from each generator by the tests.
+const samples = 200
+
+func build(t *testing.T, desc string) RandomGenerator {
+	t.Helper()
+	gens, err := BuildGenerators(desc)
+	if err != nil {
+		t.Fatalf("%s: %s", desc, err)
+	}
+	g, err := gens[0].Resolve(nil)
+	if err != nil {
+		t.Fatalf("%s: %s", desc, err)
+	}
+	return g
+}
+
+// generate draws values from a descriptor with a fixed seed.
+func generate(t *testing.T, desc string) []Value {
+	t.Helper()
+	g := build(t, desc)
+	r := rand.New(rand.NewSource(1))
+	values := make([]Value, samples)
+	for i := range values {
+		values[i] = GenerateValue(g, r)
+	}
+	return values
+}
+
+// checkErrors checks that descriptors are rejected with an error containing
+// the given message.
+func checkErrors(t *testing.T, tests map[string]string) {
+	t.Helper()
+	for desc, msg := range tests {
+		_, err := BuildGenerators(desc)
+		if err == nil {
+			t.Errorf("%s: expected an error", desc)
+		} else if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%s: got error %q, expected %q", desc, err, msg)
+		}
+	}
+}
+
+// checkUnique checks that the arrays drawn from a descriptor have the given
+// length and respect its unique, sorted and desc modifiers.
+func checkUnique(t *testing.T, desc string, length int) {
+	t.Helper()
+	g := build(t, desc).(ArrayRandomGenerator)
+	for _, value := range generate(t, desc) {
+		if len(value.Elems) != length {
+			t.Errorf("%s: %s has %d elements, expected %d", desc, value, len(value.Elems), length)
+		}
+		seen := make(map[string]bool)
+		for i, elem := range value.Elems {
+			if g.Unique && seen[elem.String()] {
+				t.Errorf("%s: %s repeats %s", desc, value, elem)
+			}
+			seen[elem.String()] = true
+			if g.Sorted && i > 0 {
+				prev, next := value.Elems[i-1], elem
+				if g.Descending {
+					prev, next = next, prev
+				}
+				if lessValues(next, prev) {
+					t.Errorf("%s: %s is not sorted", desc, value)
+				}
+			}
+		}
+	}
+}
+
+func TestTuplesAndRecords(t *testing.T) {
+	for _, value := range generate(t, "tuple(int(1,3),bool,str(2,2))") {
+		elems := value.Elems
+		if value.Kind != TupleKind || len(elems) != 3 || elems[0].Kind != IntKind || elems[1].Kind != BoolKind || len(elems[2].Text) != 2 {
+			t.Errorf("tuple(int(1,3),bool,str(2,2)): unexpected %s", value)
+		}
+	}
+	for _, value := range generate(t, "record{name:str(1,5),age:int(0,99),tags:array(0,2)[bool]}") {
+		if value.Kind != RecordKind || strings.Join(value.Names, ",") != "name,age,tags" || len(value.Elems) != 3 {
+			t.Errorf("record: unexpected %s", value)
+		} else if age, _ := value.Elems[1].Number(); age < 0 || age > 99 || value.Elems[2].Kind != ArrayKind {
+			t.Errorf("record: unexpected fields in %s", value)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"tuple":                     "tuple expects at least one component",
+		"tuple()":                   "tuple expects at least one component",
+		"tuple(bool,3)":             "expected a generator at column 12",
+		"record":                    "record expects at least one field",
+		"record{}":                  "record expects at least one field",
+		"record{a:int(0,1),a:bool}": "duplicate field 'a'",
+		"record{a:foo}":             "unknown generator 'foo'",
+	})
+}
+
+func TestMaps(t *testing.T) {
+	tests := []struct {
+		desc     string
+		min, max int
+	}{
+		{"map(3,3)[int(1,3)->str(1,2)]", 3, 3},
+		{"map(5,8)[float(0,1)|precision(1)->bool]", 5, 8},
+		{"map(10,10)[enum(a,b,c,d,e,f,g,h,i,j)->int(0,9)]", 10, 10},
+		{"map(0,1)[regex(\"[ab]\")->int(0,9)]", 0, 1},
+	}
+	for _, test := range tests {
+		for _, value := range generate(t, test.desc) {
+			if n := len(value.Keys); n < test.min || n > test.max || len(value.Elems) != n {
+				t.Errorf("%s: %s has %d keys, expected %d to %d", test.desc, value, n, test.min, test.max)
+			}
+			seen := make(map[string]bool)
+			for _, key := range value.Keys {
+				if seen[key.String()] {
+					t.Errorf("%s: %s repeats key %s", test.desc, value, key)
+				}
+				seen[key.String()] = true
+			}
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"map(4,4)[int(1,3)->bool]":         "only 3 distinct values",
+		"map(2,2)[regex(\"[ab]+\")->bool]": "cannot guarantee distinct keys",
+		"map(1,3)[normal(0,1)->bool]":      "cannot guarantee distinct keys",
+		"map(2)[int(1,3)->bool]":           "map expects 2 arguments",
+		"map(3,2)[int(1,9)->bool]":         "invalid bounds, 3 is greater than 2",
+		"map(-1,2)[int(1,9)->bool]":        "length cannot be negative",
+	})
+}
+
+func TestUniqueArrays(t *testing.T) {
+	checkUnique(t, "array(5,5)[int(1,5)]|unique", 5)
+	checkUnique(t, "array(3,3)[int(-1000000000,1000000000)]|unique|sorted", 3)
+	checkUnique(t, "array(4,4)[tuple(bool,enum(x,y))]|unique", 4)
+
+	checkErrors(t, map[string]string{
+		"array(6,6)[int(1,5)]|unique":              "only 5 distinct values",
+		"array(5,5)[tuple(bool,enum(x,y))]|unique": "only 4 distinct values",
+		"array(0,2)[array(0,1)[int(0,1)]]|unique":  "cannot guarantee distinct values",
+	})
+}
+
+func TestPermutations(t *testing.T) {
+	for _, value := range generate(t, "permutation(5)") {
+		seen := make(map[string]bool)
+		for _, elem := range value.Elems {
+			if n, _ := elem.Number(); n < 1 || n > 5 || seen[elem.Text] {
+				t.Errorf("permutation(5): %s is not a permutation", value)
+			}
+			seen[elem.Text] = true
+		}
+		if len(value.Elems) != 5 {
+			t.Errorf("permutation(5): %s has %d elements", value, len(value.Elems))
+		}
+	}
+	if values := generate(t, "permutation(0)"); len(values[0].Elems) != 0 {
+		t.Errorf("permutation(0): got %s", values[0])
+	}
+
+	checkErrors(t, map[string]string{
+		"permutation(-1)": "length cannot be negative",
+		"permutation":     "permutation expects 1 arguments",
+	})
+}
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		desc     string
+		min, max int
+		valid    func(c rune) bool
+	}{
+		{"str(0,10)", 0, 10, func(c rune) bool { return c < 128 && (unicode.IsLetter(c) || unicode.IsDigit(c)) }},
+		{`str(1,10,"abc ")`, 1, 10, func(c rune) bool { return strings.ContainsRune("abc ", c) }},
+		{`str(3,3,"éà€")`, 3, 3, func(c rune) bool { return strings.ContainsRune("éà€", c) }},
+		{"str(2,5,lower)", 2, 5, func(c rune) bool { return 'a' <= c && c <= 'z' }},
+		{"str(2,5,digits)", 2, 5, unicode.IsDigit},
+		{"str(1,4,punct)", 1, 4, func(c rune) bool { return c < 128 && unicode.IsPunct(c) || unicode.IsSymbol(c) }},
+		{"str(1,8,printable)", 1, 8, func(c rune) bool { return c < 128 && unicode.IsPrint(c) }},
+		{"str(1,8,unicode)", 1, 8, unicode.IsPrint},
+	}
+	for _, test := range tests {
+		multibyte := false
+		for _, value := range generate(t, test.desc) {
+			runes := []rune(value.Text)
+			if value.Kind != StringKind || len(runes) < test.min || len(runes) > test.max {
+				t.Errorf("%s: %q has %d characters", test.desc, value.Text, len(runes))
+			}
+			for _, c := range runes {
+				if !test.valid(c) {
+					t.Errorf("%s: unexpected character %q in %q", test.desc, c, value.Text)
+				}
+			}
+			multibyte = multibyte || len(runes) < len(value.Text)
+		}
+		if test.desc == "str(1,8,unicode)" && !multibyte {
+			t.Errorf("%s: no multibyte characters generated", test.desc)
+		}
+	}
+	checkUnique(t, `array(6,6)[str(1,1,"abcdef")]|unique|sorted|desc`, 6)
+
+	checkErrors(t, map[string]string{
+		`str(1,2,"")`:    "alphabet cannot be empty",
+		"str(1,2,greek)": "expected an alphabet string or a character class",
+		"str(2,1)":       "invalid bounds",
+		"str(-1,1)":      "cannot be negative",
+		"str(1)":         "str expects 2 or 3 arguments",
+	})
+}
+
+func TestWeightsAndEdges(t *testing.T) {
+	const draws = 4000
+	frequencies := func(desc string) map[string]float64 {
+		g := build(t, desc)
+		r := rand.New(rand.NewSource(1))
+		counts := make(map[string]float64)
+		for i := 0; i < draws; i++ {
+			counts[GenerateValue(g, r).Text] += 1.0 / draws
+		}
+		return counts
+	}
+	near := func(desc string, got float64, want float64) {
+		if math.Abs(got-want) > 0.05 {
+			t.Errorf("%s: frequency %.3f, expected %.3f", desc, got, want)
+		}
+	}
+
+	counts := frequencies("enum(a:3,b:1,c:0)")
+	near("enum(a:3,b:1,c:0)", counts["a"], 0.75)
+	near("enum(a:3,b:1,c:0)", counts["b"], 0.25)
+	if counts["c"] > 0 {
+		t.Errorf("enum(a:3,b:1,c:0): c was drawn despite its null weight")
+	}
+	counts = frequencies("enum(x,y:2)")
+	near("enum(x,y:2)", counts["x"], 1.0/3)
+
+	counts = frequencies("int(-1000,1000)|edges(1)")
+	for value := range counts {
+		if value != "-1000" && value != "1000" && value != "0" && value != "-1" && value != "1" {
+			t.Errorf("int(-1000,1000)|edges(1): %s is not an edge value", value)
+		}
+	}
+	counts = frequencies("int(5,1000)|edges(0.5)")
+	near("int(5,1000)|edges(0.5)", counts["5"]+counts["1000"], 0.5)
+	counts = frequencies("float(0,1)|edges")
+	near("float(0,1)|edges", counts["0.000000"]+counts["1.000000"], defaultEdgeBias)
+
+	checkErrors(t, map[string]string{
+		"enum(a:0,b:0)":       "enum expects at least one positive weight",
+		"int(0,9)|edges(2)":   "probability must be comprised between 0 and 1",
+		"float(0,1)|edges(x)": "expected a number",
+	})
+}
+
+func TestFloatPrecision(t *testing.T) {
+	tests := []struct {
+		desc     string
+		decimals int
+	}{
+		{"float(0,10)", 6},
+		{"float(0,10)|precision(0)", 0},
+		{"float(-1,1)|precision(3)", 3},
+		{"normal(0,5)|precision(0)", 0},
+		{"exponential(2)|precision(2)", 2},
+		{"point(0,5)|precision(0)", 0},
+		{"point(0,5)|float", 6},
+	}
+	for _, test := range tests {
+		for _, value := range generate(t, test.desc) {
+			numbers := []Value{value}
+			if value.Kind == TupleKind {
+				numbers = value.Elems
+			}
+			for _, number := range numbers {
+				decimals := 0
+				if i := strings.IndexByte(number.Text, '.'); i >= 0 {
+					decimals = len(number.Text) - i - 1
+				}
+				if number.Kind != FloatKind || decimals != test.decimals {
+					t.Errorf("%s: %s has %d decimals, expected %d", test.desc, number, decimals, test.decimals)
+				}
+			}
+		}
+	}
+
+	if n, _ := cardinality(build(t, "float(0,10)|precision(0)")); n != 11 {
+		t.Errorf("float(0,10)|precision(0) has %d distinct values, expected 11", n)
+	}
+	checkUnique(t, "array(11,11)[float(0,1)|precision(1)]|unique|sorted", 11)
+	checkErrors(t, map[string]string{
+		"float(0,1)|precision(-1)":                     "precision cannot be negative",
+		"normal(0,1)|precision(1.5)":                   "expected an integer",
+		"array(12,12)[float(0,1)|precision(1)]|unique": "only 11 distinct values",
+	})
+}
@@ -0,0 +1,588 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Lattice contains the coordinates of the points of geometry generators, that
+// are the multiples of a step comprised between two bounds. The step is 1 for
+// integer coordinates and 10^-precision for floating-point ones, so that all
+// the computations are exact.
+type Lattice struct {
+	Min       int // in steps
+	Max       int // in steps
+	Float     bool
+	Precision int // decimals, six if negative
+}
+
+// Maximum absolute coordinate, in steps, for cross products not to overflow.
+const maxLatticeCoordinate = 1 << 29
+
+// Default probability to generate a degenerate case with the degenerate modifier.
+const defaultDegeneracy = 0.2
+
+// Default bounds of the coordinates of geometry generators.
+const (
+	defaultCoordinateMin = 0
+	defaultCoordinateMax = 100
+)
+
+type point struct {
+	X int
+	Y int
+}
+
+func (l Lattice) step() float64 {
+	precision := l.Precision
+	if precision < 0 {
+		precision = 6
+	}
+	return math.Pow(10, -float64(precision))
+}
+
+func (l Lattice) random(r *rand.Rand) point {
+	return point{randint(r, l.Min, l.Max), randint(r, l.Min, l.Max)}
+}
+
+func (l Lattice) coordinate(k int) Value {
+	if l.Float {
+		return FloatValue(float64(k)*l.step(), l.Precision)
+	}
+	return IntValue(k)
+}
+
+func (l Lattice) value(p point) Value {
+	return TupleValue([]Value{l.coordinate(p.X), l.coordinate(p.Y)})
+}
+
+// cross computes the cross product of the vectors ab and ac, which is positive
+// when a, b and c are in counterclockwise order and zero when they are collinear.
+func cross(a point, b point, c point) int {
+	return (b.X-a.X)*(c.Y-a.Y) - (b.Y-a.Y)*(c.X-a.X)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// point
+
+type PointRandomGenerator struct {
+	Lattice Lattice
+}
+
+// Generates a random point (x y) whose coordinates are comprised between two
+// bounds.
+func (g PointRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return g.Lattice.value(g.Lattice.random(r))
+}
+
+func (g PointRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// point[(min,max)]
+func newPointGenerator(p *Params) (RandomGenerator, error) {
+	lattice, err := lattice(p, 0)
+	if err != nil {
+		return nil, err
+	}
+	return PointRandomGenerator{lattice}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// segment
+
+type SegmentRandomGenerator struct {
+	Lattice    Lattice
+	Degeneracy float64
+}
+
+// Generates a random segment ((x1 y1) (x2 y2)) whose endpoints are distinct,
+// or a degenerate one with a probability given by the degeneracy, that is a
+// segment whose endpoints are equal or that is vertical or horizontal.
+func (g SegmentRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	a := g.Lattice.random(r)
+	b := g.Lattice.random(r)
+	if g.Degeneracy > 0 && r.Float64() < g.Degeneracy {
+		switch r.Intn(3) {
+		case 0:
+			b = a
+		case 1:
+			b.X = a.X
+		case 2:
+			b.Y = a.Y
+		}
+	} else {
+		for attempts := 0; a == b && attempts < maxAttempts; attempts++ {
+			b = g.Lattice.random(r)
+		}
+	}
+	return TupleValue([]Value{g.Lattice.value(a), g.Lattice.value(b)})
+}
+
+func (g SegmentRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// segment[(min,max)]
+func newSegmentGenerator(p *Params) (RandomGenerator, error) {
+	lattice, err := lattice(p, 0)
+	if err != nil {
+		return nil, err
+	}
+	degeneracy, err := probabilityModifier(p, "degenerate", defaultDegeneracy)
+	if err != nil {
+		return nil, err
+	}
+	if lattice.Min == lattice.Max && degeneracy < 1 {
+		return nil, p.Errorf("bounds are too narrow for segments with distinct endpoints")
+	}
+	return SegmentRandomGenerator{lattice, degeneracy}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// polygon
+
+type PolygonRandomGenerator struct {
+	MinVertices int
+	MaxVertices int
+	Convex      bool
+	Lattice     Lattice
+	Degeneracy  float64
+}
+
+// Generates a random polygon, as the list of its vertices in counterclockwise
+// order, that is either convex or simple. Polygons are degenerate with a
+// probability given by the degeneracy, having three consecutive collinear
+// vertices or, failing that, a repeated vertex.
+func (g PolygonRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	n := randint(r, g.MinVertices, g.MaxVertices)
+	degenerate := g.Degeneracy > 0 && r.Float64() < g.Degeneracy
+
+	var vertices []point
+	switch {
+	case degenerate && n == 3:
+		vertices = g.collinear(r)
+	case degenerate:
+		vertices = g.insertCollinear(r, g.generate(r, n-1))
+	default:
+		vertices = g.generate(r, n)
+	}
+
+	data := make([]Value, len(vertices))
+	for i, v := range vertices {
+		data[i] = g.Lattice.value(v)
+	}
+	return ArrayValue(data)
+}
+
+func (g PolygonRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+func (g PolygonRandomGenerator) generate(r *rand.Rand, n int) []point {
+	var vertices []point
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		var ok bool
+		if g.Convex {
+			vertices, ok = g.generateConvex(r, n)
+		} else {
+			vertices, ok = g.generateSimple(r, n)
+		}
+		if ok {
+			return vertices
+		}
+	}
+	return g.construct(r, n)
+}
+
+// construct builds a polygon with n vertices, for bounds too narrow for random
+// ones to be found, and places it at random within the bounds. Simple polygons
+// are zigzags, unless there is not enough room for them, and convex ones are
+// made of some of the vertices of the largest polygon that fits the bounds.
+func (g PolygonRandomGenerator) construct(r *rand.Rand, n int) []point {
+	size := g.Lattice.Max - g.Lattice.Min
+	vertices := zigzagPolygon(size, n)
+	if g.Convex || vertices == nil {
+		// Any subset of the vertices of a strictly convex polygon is strictly convex.
+		all := convexPolygon(size, n)
+		keep := r.Perm(len(all))[:n]
+		sort.Ints(keep)
+		vertices = make([]point, n)
+		for i, k := range keep {
+			vertices[i] = all[k]
+		}
+	}
+
+	width, height := 0, 0
+	for _, v := range vertices {
+		if v.X > width {
+			width = v.X
+		}
+		if v.Y > height {
+			height = v.Y
+		}
+	}
+	dx := g.Lattice.Min + randint(r, 0, size-width)
+	dy := g.Lattice.Min + randint(r, 0, size-height)
+	k := randint(r, 0, n-1)
+	result := make([]point, n)
+	for i := range result {
+		v := vertices[(i+k)%n]
+		result[i] = point{v.X + dx, v.Y + dy}
+	}
+	return result
+}
+
+// generateConvex generates a convex polygon with Valtr's algorithm, that
+// splits random coordinates into two chains to build the vectors of the edges,
+// sorted by angle. The polygon is then stretched to the bounds and rounded,
+// which can make it lose its strict convexity.
+func (g PolygonRandomGenerator) generateConvex(r *rand.Rand, n int) ([]point, bool) {
+	vectors := func() []float64 {
+		coordinates := make([]float64, n)
+		for i := range coordinates {
+			coordinates[i] = r.Float64()
+		}
+		sort.Float64s(coordinates)
+
+		min, max := coordinates[0], coordinates[n-1]
+		result := make([]float64, 0, n)
+		last1, last2 := min, min
+		for _, c := range coordinates[1 : n-1] {
+			if r.Intn(2) == 0 {
+				result = append(result, c-last1)
+				last1 = c
+			} else {
+				result = append(result, last2-c)
+				last2 = c
+			}
+		}
+		return append(result, max-last1, last2-max)
+	}
+	xs, ys := vectors(), vectors()
+	r.Shuffle(len(ys), func(i, j int) {
+		ys[i], ys[j] = ys[j], ys[i]
+	})
+
+	// Laying the vectors sorted by angle end to end makes a convex polygon.
+	order := make([]int, n)
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return math.Atan2(ys[order[i]], xs[order[i]]) < math.Atan2(ys[order[j]], xs[order[j]])
+	})
+	px, py := make([]float64, n), make([]float64, n)
+	for k := 1; k < n; k++ {
+		px[k] = px[k-1] + xs[order[k-1]]
+		py[k] = py[k-1] + ys[order[k-1]]
+	}
+
+	vertices := stretch(px, py, g.Lattice)
+	for i := range vertices {
+		if cross(vertices[i], vertices[(i+1)%n], vertices[(i+2)%n]) <= 0 {
+			return vertices, false
+		}
+	}
+	return vertices, true
+}
+
+// generateSimple generates a simple polygon by sorting random distinct points
+// by angle around their centroid, which makes a star-shaped polygon.
+func (g PolygonRandomGenerator) generateSimple(r *rand.Rand, n int) ([]point, bool) {
+	seen := make(map[point]bool, n)
+	vertices := make([]point, 0, n)
+	cx, cy := 0.0, 0.0
+	for attempts := 0; len(vertices) < n && attempts < maxAttempts*n; attempts++ {
+		if v := g.Lattice.random(r); !seen[v] {
+			seen[v] = true
+			vertices = append(vertices, v)
+			cx += float64(v.X)
+			cy += float64(v.Y)
+		}
+	}
+	if len(vertices) < n {
+		return vertices, false
+	}
+
+	cx, cy = cx/float64(n), cy/float64(n)
+	sort.Slice(vertices, func(i, j int) bool {
+		return math.Atan2(float64(vertices[i].Y)-cy, float64(vertices[i].X)-cx) < math.Atan2(float64(vertices[j].Y)-cy, float64(vertices[j].X)-cx)
+	})
+	for i := range vertices {
+		if cross(vertices[i], vertices[(i+1)%n], vertices[(i+2)%n]) == 0 {
+			return vertices, false
+		}
+	}
+	return vertices, isSimple(vertices)
+}
+
+// collinear generates three distinct collinear vertices, the second one being
+// the middle of the two others.
+func (g PolygonRandomGenerator) collinear(r *rand.Rand) []point {
+	a, c := g.Lattice.random(r), g.Lattice.random(r)
+	for attempts := 0; attempts < maxAttempts; attempts++ {
+		if a != c && (a.X-c.X)%2 == 0 && (a.Y-c.Y)%2 == 0 {
+			break
+		}
+		c = g.Lattice.random(r)
+	}
+	return []point{a, {(a.X + c.X) / 2, (a.Y + c.Y) / 2}, c}
+}
+
+// insertCollinear inserts the middle of an edge of a polygon, among the edges
+// whose middle is on the lattice, or repeats a vertex if there are none.
+func (g PolygonRandomGenerator) insertCollinear(r *rand.Rand, vertices []point) []point {
+	n := len(vertices)
+	var candidates []int
+	for i := range vertices {
+		a, b := vertices[i], vertices[(i+1)%n]
+		if (a.X-b.X)%2 == 0 && (a.Y-b.Y)%2 == 0 {
+			candidates = append(candidates, i)
+		}
+	}
+
+	i := randint(r, 0, n-1)
+	v := vertices[i]
+	if len(candidates) > 0 {
+		i = candidates[randint(r, 0, len(candidates)-1)]
+		a, b := vertices[i], vertices[(i+1)%n]
+		v = point{(a.X + b.X) / 2, (a.Y + b.Y) / 2}
+	}
+	result := append([]point{}, vertices[:i+1]...)
+	result = append(result, v)
+	return append(result, vertices[i+1:]...)
+}
+
+// polygon(n,convex|simple[,min,max])
+func newPolygonGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() != 2 && p.Len() != 4 {
+		return nil, p.Errorf("polygon expects 2 or 4 arguments")
+	}
+	min, max, err := p.Arg(0).Range()
+	if err != nil {
+		return nil, err
+	}
+	if min < 3 || min > max {
+		return nil, p.Arg(0).Errorf("number of vertices must be at least 3")
+	}
+	kind, err := p.Arg(1).Ident()
+	if err != nil || (kind != "convex" && kind != "simple") {
+		return nil, p.Arg(1).Errorf("expected convex or simple")
+	}
+	lattice, err := lattice(p, 2)
+	if err != nil {
+		return nil, err
+	}
+	size := lattice.Max - lattice.Min
+	if len(convexPolygon(size, max)) < max && (kind == "convex" || zigzagPolygon(size, max) == nil) {
+		return nil, p.Errorf("bounds are too narrow for %s polygons with %d vertices", kind, max)
+	}
+	degeneracy, err := probabilityModifier(p, "degenerate", defaultDegeneracy)
+	if err != nil {
+		return nil, err
+	}
+	return PolygonRandomGenerator{min, max, kind == "convex", lattice, degeneracy}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Utility functions
+
+// lattice reads the bounds of the coordinates, from the positional arguments
+// starting at the given index, if any, and the float and precision modifiers.
+func lattice(p *Params, start int) (Lattice, error) {
+	min, max := float64(defaultCoordinateMin), float64(defaultCoordinateMax)
+	if p.Len() > start {
+		if p.Len() != start+2 {
+			return Lattice{}, p.Errorf("%s expects %d arguments", p.Name(), start+2)
+		}
+		var err error
+		if min, err = p.Arg(start).Float(); err != nil {
+			return Lattice{}, err
+		}
+		if max, err = p.Arg(start + 1).Float(); err != nil {
+			return Lattice{}, err
+		}
+		if min > max {
+			return Lattice{}, p.Errorf("invalid bounds, %g is greater than %g", min, max)
+		}
+	}
+
+	decimals, err := precision(p)
+	if err != nil {
+		return Lattice{}, err
+	}
+	l := Lattice{Float: p.Modifier("float") != nil || decimals >= 0, Precision: decimals}
+	step := 1.0
+	if l.Float {
+		step = l.step()
+	}
+	lo, hi := math.Ceil(min/step-1e-9), math.Floor(max/step+1e-9)
+	if lo > hi {
+		return Lattice{}, p.Errorf("no coordinates between %g and %g", min, max)
+	}
+	if math.Abs(lo) > maxLatticeCoordinate || math.Abs(hi) > maxLatticeCoordinate {
+		return Lattice{}, p.Errorf("bounds are too wide for the precision of the coordinates")
+	}
+	l.Min, l.Max = int(lo), int(hi)
+	return l, nil
+}
+
+// stretch maps points to the lattice, so that they span the whole bounds.
+func stretch(xs []float64, ys []float64, l Lattice) []point {
+	scale := func(cs []float64) []int {
+		min, max := cs[0], cs[0]
+		for _, c := range cs {
+			min, max = math.Min(min, c), math.Max(max, c)
+		}
+		result := make([]int, len(cs))
+		for i, c := range cs {
+			if max > min {
+				result[i] = l.Min + int(math.Round((c-min)/(max-min)*float64(l.Max-l.Min)))
+			} else {
+				result[i] = l.Min
+			}
+		}
+		return result
+	}
+
+	x, y := scale(xs), scale(ys)
+	points := make([]point, len(xs))
+	for i := range points {
+		points[i] = point{x[i], y[i]}
+	}
+	return points
+}
+
+// convexPolygon builds a strictly convex polygon within [0,size]x[0,size], with
+// at least n vertices if possible, or with as many as possible otherwise. Its
+// edges are opposite pairs of primitive vectors, the shortest ones first, laid
+// end to end by angle.
+func convexPolygon(size int, n int) []point {
+	var edges []point
+	width, height := 0, 0
+	for s := 1; s <= 2*size-width-height && len(edges) < n; s++ {
+		for a := 0; a <= s && len(edges) < n; a++ {
+			b := s - a
+			if gcd(a, b) != 1 || width+a > size || height+b > size {
+				continue
+			}
+			edges = append(edges, point{a, b}, point{-a, -b})
+			width, height = width+a, height+b
+			if a > 0 && b > 0 && len(edges) < n && width+a <= size && height+b <= size {
+				edges = append(edges, point{a, -b}, point{-a, b})
+				width, height = width+a, height+b
+			}
+		}
+	}
+	sort.Slice(edges, func(i, j int) bool {
+		return math.Atan2(float64(edges[i].Y), float64(edges[i].X)) < math.Atan2(float64(edges[j].Y), float64(edges[j].X))
+	})
+
+	vertices := make([]point, len(edges))
+	minX, minY := 0, 0
+	for i := 1; i < len(edges); i++ {
+		vertices[i] = point{vertices[i-1].X + edges[i-1].X, vertices[i-1].Y + edges[i-1].Y}
+		if vertices[i].X < minX {
+			minX = vertices[i].X
+		}
+		if vertices[i].Y < minY {
+			minY = vertices[i].Y
+		}
+	}
+	for i := range vertices {
+		vertices[i].X -= minX
+		vertices[i].Y -= minY
+	}
+	return vertices
+}
+
+// zigzagPolygon builds a simple polygon with n vertices within [0,size]x[0,size],
+// made of two zigzags along the bottom and the top of the bounds and, for odd
+// numbers of vertices, an additional vertex on the right. It returns nil if
+// there is not enough room, or fewer than five vertices.
+func zigzagPolygon(size int, n int) []point {
+	m := n / 2
+	if n < 5 || size < 3 || m-1+n%2 > size {
+		return nil
+	}
+
+	vertices := make([]point, 0, n)
+	for x := 0; x < m; x++ {
+		vertices = append(vertices, point{x, x % 2})
+	}
+	if n%2 == 1 {
+		for y := 0; y <= size; y++ {
+			v := point{m, y}
+			a, b := vertices[m-2], vertices[m-1]
+			c, d := point{m - 1, size - b.Y}, point{m - 2, size - a.Y}
+			if cross(a, b, v) != 0 && cross(v, c, d) != 0 {
+				vertices = append(vertices, v)
+				break
+			}
+		}
+	}
+	for x := m - 1; x >= 0; x-- {
+		vertices = append(vertices, point{x, size - x%2})
+	}
+	return vertices
+}
+
+// isSimple checks whether the edges of a polygon only meet at their common
+// vertices.
+func isSimple(vertices []point) bool {
+	n := len(vertices)
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if intersect(vertices[i], vertices[i+1], vertices[j], vertices[(j+1)%n]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// intersect checks whether the segments ab and cd have a common point.
+func intersect(a point, b point, c point, d point) bool {
+	d1, d2 := cross(c, d, a), cross(c, d, b)
+	d3, d4 := cross(a, b, c), cross(a, b, d)
+	if ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) && ((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0)) {
+		return true
+	}
+	on := func(p point, q point, s point) bool {
+		return math.Min(float64(p.X), float64(q.X)) <= float64(s.X) && float64(s.X) <= math.Max(float64(p.X), float64(q.X)) &&
+			math.Min(float64(p.Y), float64(q.Y)) <= float64(s.Y) && float64(s.Y) <= math.Max(float64(p.Y), float64(q.Y))
+	}
+	return (d1 == 0 && on(c, d, a)) || (d2 == 0 && on(c, d, b)) || (d3 == 0 && on(a, b, c)) || (d4 == 0 && on(a, b, d))
+}
+
+// gcd computes the greatest common divisor of two non-negative integers.
+func gcd(a int, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -0,0 +1,129 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+// checkPolygon checks that a polygon has n vertices within [min,max]², that it
+// is simple and in counterclockwise order, without three consecutive collinear
+// vertices, and that it is convex if required.
+func checkPolygon(t *testing.T, name string, vertices []point, n int, min int, max int, convex bool) {
+	t.Helper()
+	if len(vertices) != n {
+		t.Errorf("%s: %v has %d vertices, expected %d", name, vertices, len(vertices), n)
+		return
+	}
+	area := 0
+	for i, v := range vertices {
+		if v.X < min || v.X > max || v.Y < min || v.Y > max {
+			t.Errorf("%s: %v has vertex %v out of bounds", name, vertices, v)
+		}
+		c := cross(v, vertices[(i+1)%n], vertices[(i+2)%n])
+		if c == 0 || (convex && c < 0) {
+			t.Errorf("%s: %v is not strictly convex or has collinear vertices at %v", name, vertices, vertices[(i+1)%n])
+		}
+		w := vertices[(i+1)%n]
+		area += v.X*w.Y - w.X*v.Y
+	}
+	if area <= 0 {
+		t.Errorf("%s: %v is not in counterclockwise order", name, vertices)
+	}
+	if !isSimple(vertices) {
+		t.Errorf("%s: %v is not simple", name, vertices)
+	}
+}
+
+func TestPolygons(t *testing.T) {
+	tests := []struct {
+		desc     string
+		min, max int
+		lo, hi   int
+		convex   bool
+	}{
+		{"polygon(3..10,convex)", 3, 10, 0, 100, true},
+		{"polygon(3..10,simple)", 3, 10, 0, 100, false},
+		{"polygon(6,convex,0,2)", 6, 6, 0, 2, true},
+		{"polygon(12,convex,-3,3)", 12, 12, -3, 3, true},
+		{"polygon(7..8,simple,0,3)", 7, 8, 0, 3, false},
+		{"polygon(4,simple,5,6)", 4, 4, 5, 6, false},
+	}
+	for _, test := range tests {
+		for _, value := range generate(t, test.desc) {
+			vertices := make([]point, len(value.Elems))
+			for i, elem := range value.Elems {
+				x, _ := strconv.Atoi(elem.Elems[0].Text)
+				y, _ := strconv.Atoi(elem.Elems[1].Text)
+				vertices[i] = point{x, y}
+			}
+			n := len(vertices)
+			if n < test.min || n > test.max {
+				t.Errorf("%s: %s has %d vertices", test.desc, value, n)
+				continue
+			}
+			checkPolygon(t, test.desc, vertices, n, test.lo, test.hi, test.convex)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"polygon(2,convex)":        "at least 3",
+		"polygon(3,concave)":       "expected convex or simple",
+		"polygon(7,convex,0,2)":    "too narrow for convex polygons with 7 vertices",
+		"polygon(13,convex,0,6)":   "too narrow for convex polygons with 13 vertices",
+		"polygon(9,simple,0,3)":    "too narrow for simple polygons with 9 vertices",
+		"polygon(3..5,simple,1,1)": "too narrow for simple polygons with 5 vertices",
+	})
+}
+
+func TestSegments(t *testing.T) {
+	checkErrors(t, map[string]string{
+		"segment(0,0)|degenerate(0)": "too narrow for segments with distinct endpoints",
+		"segment(0.5,1.4)":           "too narrow for segments with distinct endpoints",
+	})
+	for _, v := range generate(t, "segment(3,3)|degenerate(1)") {
+		if v.String() != "((3 3) (3 3))" {
+			t.Fatalf("unexpected segment %s", v)
+		}
+	}
+	for _, v := range generate(t, "segment(0,1)|degenerate(0)") {
+		if v.Elems[0].String() == v.Elems[1].String() {
+			t.Fatalf("segment %s is degenerate", v)
+		}
+	}
+}
+
+func TestConstructedPolygons(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for size := 0; size <= 8; size++ {
+		for n := 3; n <= 40; n++ {
+			for _, convex := range []bool{true, false} {
+				if len(convexPolygon(size, n)) < n && (convex || zigzagPolygon(size, n) == nil) {
+					continue
+				}
+				g := PolygonRandomGenerator{n, n, convex, Lattice{Min: 10, Max: 10 + size}, 0}
+				name := "polygon(" + strconv.Itoa(n) + ") within " + strconv.Itoa(size)
+				checkPolygon(t, name, g.construct(r, n), n, 10, 10+size, convex)
+			}
+		}
+	}
+}
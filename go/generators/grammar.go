@@ -0,0 +1,278 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// grammar
+
+type GrammarRandomGenerator struct {
+	Grammar  *Grammar
+	MaxDepth int
+}
+
+// Generates a random sentence derived from a context-free grammar, whose
+// derivation tree has at most the given depth. Alternatives are chosen
+// uniformly among the ones that can be completed within the remaining depth.
+func (g GrammarRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	var sb strings.Builder
+	g.Grammar.derive(r, &sb, g.Grammar.Start, g.MaxDepth)
+	return StringValue(sb.String())
+}
+
+func (g GrammarRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// grammar("file.bnf",maxdepth)
+func newGrammarGenerator(p *Params) (RandomGenerator, error) {
+	var path string
+	var depth int
+	if err := p.Scan(&path, &depth); err != nil {
+		return nil, err
+	}
+	if depth < 1 {
+		return nil, p.Arg(1).Errorf("depth must be positive")
+	}
+	content, err := loadFile("grammar", path, func(data []byte) (interface{}, error) {
+		return ParseGrammar(string(data))
+	})
+	if err != nil {
+		return nil, p.Arg(0).Errorf("%s", err)
+	}
+
+	grammar := content.(*Grammar)
+	if min := grammar.depths[grammar.Start]; min > depth {
+		return nil, p.Arg(1).Errorf("grammar requires a depth of at least %d", min)
+	}
+	return GrammarRandomGenerator{grammar, depth}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Grammars
+
+// Symbol is a terminal or a nonterminal symbol of a grammar.
+type Symbol struct {
+	Text        string
+	Nonterminal bool
+}
+
+// Grammar is a context-free grammar, whose rules give the alternatives of each
+// nonterminal symbol. Sentences are derived from the start symbol, that is the
+// one of the first rule.
+type Grammar struct {
+	Start string
+	Rules map[string][][]Symbol
+
+	// Minimum depth of the derivation trees of the nonterminal symbols.
+	depths map[string]int
+}
+
+// derive writes a sentence derived from a nonterminal symbol, whose derivation
+// tree has at most the given depth.
+func (g *Grammar) derive(r *rand.Rand, sb *strings.Builder, symbol string, depth int) {
+	var alternatives [][]Symbol
+	for _, alternative := range g.Rules[symbol] {
+		if d, ok := g.depth(alternative); ok && d <= depth {
+			alternatives = append(alternatives, alternative)
+		}
+	}
+
+	for _, s := range alternatives[randint(r, 0, len(alternatives)-1)] {
+		if s.Nonterminal {
+			g.derive(r, sb, s.Text, depth-1)
+		} else {
+			sb.WriteString(s.Text)
+		}
+	}
+}
+
+// depth computes the minimum depth of the derivation trees of an alternative,
+// if it has any.
+func (g *Grammar) depth(alternative []Symbol) (int, bool) {
+	depth := 1
+	for _, s := range alternative {
+		if s.Nonterminal {
+			d, ok := g.depths[s.Text]
+			if !ok {
+				return 0, false
+			}
+			if d+1 > depth {
+				depth = d + 1
+			}
+		}
+	}
+	return depth, true
+}
+
+// ParseGrammar parses a grammar in BNF, made of rules such as the following
+// ones, that can span several lines. Terminal symbols are quoted with double
+// quotes, which can contain escape sequences, or with single quotes, and
+// comments start with # and run until the end of the line.
+//
+//	<expr> ::= <term> | <term> "+" <expr>
+//	<term> ::= "x" | "(" <expr> ")"
+func ParseGrammar(src string) (*Grammar, error) {
+	tokens, err := tokenizeGrammar(src)
+	if err != nil {
+		return nil, err
+	}
+
+	g := &Grammar{Rules: make(map[string][][]Symbol)}
+	lines := make(map[string]int)
+	for i := 0; i < len(tokens); {
+		if tokens[i].kind != grammarNonterminal || i+1 == len(tokens) || tokens[i+1].kind != grammarDefine {
+			return nil, fmt.Errorf("line %d: expected a rule", tokens[i].line)
+		}
+		name := tokens[i].text
+		if _, ok := g.Rules[name]; ok {
+			return nil, fmt.Errorf("line %d: duplicate rule for <%s>", tokens[i].line, name)
+		}
+		if g.Start == "" {
+			g.Start = name
+		}
+		lines[name] = tokens[i].line
+
+		// Alternatives run until the next rule.
+		alternatives := [][]Symbol{{}}
+		for i += 2; i < len(tokens); i++ {
+			t := tokens[i]
+			if t.kind == grammarNonterminal && i+1 < len(tokens) && tokens[i+1].kind == grammarDefine {
+				break
+			}
+			switch t.kind {
+			case grammarOr:
+				alternatives = append(alternatives, []Symbol{})
+			case grammarDefine:
+				return nil, fmt.Errorf("line %d: unexpected '::='", t.line)
+			default:
+				last := len(alternatives) - 1
+				alternatives[last] = append(alternatives[last], Symbol{t.text, t.kind == grammarNonterminal})
+			}
+		}
+		g.Rules[name] = alternatives
+	}
+	if g.Start == "" {
+		return nil, fmt.Errorf("grammar has no rules")
+	}
+
+	for _, t := range tokens {
+		if _, ok := g.Rules[t.text]; t.kind == grammarNonterminal && !ok {
+			return nil, fmt.Errorf("line %d: undefined nonterminal <%s>", t.line, t.text)
+		}
+	}
+
+	// The minimum depths are computed until a fixed point is reached, leaving
+	// out the nonterminal symbols from which no sentence can be derived.
+	g.depths = make(map[string]int)
+	for changed := true; changed; {
+		changed = false
+		for name, alternatives := range g.Rules {
+			for _, alternative := range alternatives {
+				d, ok := g.depth(alternative)
+				if min, found := g.depths[name]; ok && (!found || d < min) {
+					g.depths[name] = d
+					changed = true
+				}
+			}
+		}
+	}
+	if _, ok := g.depths[g.Start]; !ok {
+		return nil, fmt.Errorf("line %d: no sentence can be derived from <%s>", lines[g.Start], g.Start)
+	}
+	return g, nil
+}
+
+type grammarTokenKind int
+
+const (
+	grammarNonterminal grammarTokenKind = iota
+	grammarTerminal
+	grammarDefine
+	grammarOr
+)
+
+type grammarToken struct {
+	kind grammarTokenKind
+	text string
+	line int
+}
+
+func tokenizeGrammar(src string) ([]grammarToken, error) {
+	var tokens []grammarToken
+	line := 1
+	for i := 0; i < len(src); {
+		switch c := src[i]; {
+		case c == '\n':
+			line++
+			i++
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+		case c == '#':
+			for i < len(src) && src[i] != '\n' {
+				i++
+			}
+		case c == '|':
+			tokens = append(tokens, grammarToken{grammarOr, "|", line})
+			i++
+		case strings.HasPrefix(src[i:], "::="):
+			tokens = append(tokens, grammarToken{grammarDefine, "::=", line})
+			i += 3
+		case c == '<':
+			end := strings.IndexAny(src[i+1:], ">\n")
+			if end <= 0 || src[i+1+end] != '>' {
+				return nil, fmt.Errorf("line %d: invalid nonterminal", line)
+			}
+			tokens = append(tokens, grammarToken{grammarNonterminal, src[i+1 : i+1+end], line})
+			i += end + 2
+		case c == '"' || c == '\'':
+			// Find the closing quote, skipping escaped characters in double
+			// quoted terminals.
+			end := i + 1
+			for end < len(src) && src[end] != c && src[end] != '\n' {
+				if c == '"' && src[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(src) || src[end] != c {
+				return nil, fmt.Errorf("line %d: unterminated terminal", line)
+			}
+			text := src[i+1 : end]
+			if c == '"' {
+				var err error
+				if text, err = strconv.Unquote(src[i : end+1]); err != nil {
+					return nil, fmt.Errorf("line %d: invalid terminal %s", line, src[i:end+1])
+				}
+			}
+			tokens = append(tokens, grammarToken{grammarTerminal, text, line})
+			i = end + 1
+		default:
+			return nil, fmt.Errorf("line %d: unexpected '%c'", line, c)
+		}
+	}
+	return tokens, nil
+}
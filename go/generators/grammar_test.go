@@ -0,0 +1,142 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// writeFiles writes files into a temporary directory, that is removed by the
+// returned function.
+func writeFiles(t *testing.T, contents map[string]string) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "generators")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range contents {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			os.RemoveAll(dir)
+			t.Fatal(err)
+		}
+	}
+	return dir, func() { os.RemoveAll(dir) }
+}
+
+var bitSum = regexp.MustCompile(`\([01]\+[01]\)`)
+
+func TestGrammar(t *testing.T) {
+	dir, remove := writeFiles(t, map[string]string{
+		"expr.bnf":  "# Sums of bits\n<expr> ::= <num>\n  | \"(\" <expr> '+' <expr> \")\"\n<num> ::= \"0\" | \"1\"\n",
+		"paren.bnf": "<s> ::= \"\" | \"(\" <s> \")\" <s>",
+	})
+	defer remove()
+	defer func(dir string) { Dir = dir }(Dir)
+	Dir = dir
+
+	// Sentences are reduced to a single bit, and the number of nested sums
+	// is bounded by the depth.
+	for _, v := range generate(t, `grammar("expr.bnf",4)`) {
+		s := v.String()
+		for depth := 0; ; depth++ {
+			if s == "0" || s == "1" {
+				break
+			}
+			if reduced := bitSum.ReplaceAllString(s, "0"); depth < 2 && reduced != s {
+				s = reduced
+				continue
+			}
+			t.Fatalf("sentence %q cannot be derived within depth 4", v)
+		}
+	}
+
+	// Parentheses are balanced and nested at most depth-1 times.
+	longest := 0
+	for _, v := range generate(t, `grammar("paren.bnf",5)`) {
+		open, max := 0, 0
+		for _, c := range v.String() {
+			if c == '(' {
+				open++
+			} else {
+				open--
+			}
+			if open < 0 {
+				t.Fatalf("unbalanced sentence %q", v)
+			}
+			if open > max {
+				max = open
+			}
+		}
+		if open != 0 || max > 4 {
+			t.Fatalf("sentence %q is unbalanced or too deep", v)
+		}
+		if len(v.String()) > longest {
+			longest = len(v.String())
+		}
+	}
+	if longest == 0 {
+		t.Errorf("only empty sentences were generated")
+	}
+
+	checkErrors(t, map[string]string{
+		`grammar("expr.bnf",0)`:    "depth must be positive",
+		`grammar("expr.bnf",1)`:    "grammar requires a depth of at least 2",
+		`grammar("missing.bnf",3)`: "missing.bnf",
+	})
+}
+
+func TestParseGrammarErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                               "grammar has no rules",
+		"# nothing\n":                    "grammar has no rules",
+		"\"a\" ::= <b>":                  "line 1: expected a rule",
+		"<a> ::= \"x\"\n<a> ::= \"y\"":   "line 2: duplicate rule for <a>",
+		"<a> ::= <b>":                    "line 1: undefined nonterminal <b>",
+		"<a> ::= <b>\n<b> ::= \"x\" <b>": "line 1: no sentence can be derived from <a>",
+		"<a> ::= \"x":                    "line 1: unterminated terminal",
+		"<a> ::= \"x\"\n<b ::= \"y\"":    "line 2: invalid nonterminal",
+		"<a> ::= \"x\" ; \"y\"":          "line 1: unexpected ';'",
+		"<a> ::= \"\\q\"":                "line 1: invalid terminal",
+		"<a> ::= \"x\" ::= \"y\"":        "line 1: unexpected '::='",
+	}
+	for src, msg := range tests {
+		if _, err := ParseGrammar(src); err == nil {
+			t.Errorf("%q: expected an error", src)
+		} else if !strings.Contains(err.Error(), msg) {
+			t.Errorf("%q: got error %q, expected %q", src, err, msg)
+		}
+	}
+
+	g, err := ParseGrammar("<a> ::= 'x\\' \"\\ty\" | <b>\n<b> ::= # empty\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Start != "a" || len(g.Rules["a"]) != 2 || len(g.Rules["b"]) != 1 || len(g.Rules["b"][0]) != 0 {
+		t.Fatalf("unexpected grammar %+v", g)
+	}
+	if s := g.Rules["a"][0]; s[0].Text != "x\\" || s[1].Text != "\ty" || s[0].Nonterminal {
+		t.Errorf("unexpected terminals %+v", s)
+	}
+}
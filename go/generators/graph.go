@@ -0,0 +1,249 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"sort"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// graph, tree and dag
+
+// Graph is a random graph whose vertices are numbered from 0 to N-1.
+type Graph struct {
+	N     int
+	Edges [][2]int
+}
+
+type GraphRandomGenerator struct {
+	MinNodes  int
+	MaxNodes  int
+	MinEdges  int
+	MaxEdges  int // -1 to use the default density
+	Connected bool
+	Directed  bool
+	Acyclic   bool
+	Tree      bool
+	Weights   RandomGenerator // nil for unweighted graphs
+	Adjacency bool
+}
+
+// Generates a random graph, as a sorted list of edges (u v) or (u v w) for
+// weighted graphs, or as a map from each vertex to its sorted neighbours.
+func (g GraphRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	graph := g.generateGraph(r)
+	weights := make([]Value, len(graph.Edges))
+	if g.Weights != nil {
+		for i := range weights {
+			weights[i] = GenerateValue(g.Weights, r)
+		}
+	}
+
+	if g.Adjacency {
+		vertices := make([]Value, graph.N)
+		neighbours := make([]Value, graph.N)
+		for u := range vertices {
+			vertices[u] = IntValue(u)
+			neighbours[u] = ArrayValue([]Value{})
+		}
+		for i, edge := range graph.Edges {
+			u, v := edge[0], edge[1]
+			neighbours[u].Elems = append(neighbours[u].Elems, g.neighbour(v, weights[i]))
+			if !g.Directed {
+				neighbours[v].Elems = append(neighbours[v].Elems, g.neighbour(u, weights[i]))
+			}
+		}
+		return MapValue(vertices, neighbours)
+	}
+
+	edges := make([]Value, len(graph.Edges))
+	for i, edge := range graph.Edges {
+		data := []Value{IntValue(edge[0]), IntValue(edge[1])}
+		if g.Weights != nil {
+			data = append(data, weights[i])
+		}
+		edges[i] = TupleValue(data)
+	}
+	return ArrayValue(edges)
+}
+
+func (g GraphRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+func (g GraphRandomGenerator) neighbour(v int, weight Value) Value {
+	if g.Weights != nil {
+		return TupleValue([]Value{IntValue(v), weight})
+	}
+	return IntValue(v)
+}
+
+// edgeBounds computes the minimum and maximum number of edges of a graph with
+// n vertices satisfying the constraints of the generator.
+func (g GraphRandomGenerator) edgeBounds(n int) (int, int) {
+	min, max := 0, n*(n-1)/2
+	if g.Directed && !g.Acyclic {
+		max = n * (n - 1)
+	}
+	if g.Connected && n > 0 {
+		min = n - 1
+	}
+	if g.Tree {
+		max = min
+	}
+	return min, max
+}
+
+// generateGraph draws a random graph with sorted edges. Connected graphs are
+// built from a random spanning tree, and acyclic graphs only have edges going
+// forward in a random topological order.
+func (g GraphRandomGenerator) generateGraph(r *rand.Rand) Graph {
+	n := randint(r, g.MinNodes, g.MaxNodes)
+	min, max := g.edgeBounds(n)
+	lo, hi := g.MinEdges, g.MaxEdges
+	if hi < 0 {
+		hi = 2 * n
+	}
+	if lo < min {
+		lo = min
+	}
+	if hi > max {
+		hi = max
+	}
+	m := lo
+	if lo < hi {
+		m = randint(r, lo, hi)
+	}
+	if m > max {
+		m = max
+	}
+
+	order := r.Perm(n)
+	edge := func(u int, v int) [2]int {
+		if !g.Directed && order[u] > order[v] {
+			return [2]int{order[v], order[u]}
+		}
+		return [2]int{order[u], order[v]}
+	}
+
+	// Spanning tree attaching each vertex to a previous one in the random order.
+	used := make(map[[2]int]bool, m)
+	edges := make([][2]int, 0, m)
+	if g.Connected {
+		for v := 1; v < n; v++ {
+			e := edge(randint(r, 0, v-1), v)
+			if g.Directed && !g.Acyclic && r.Intn(2) == 0 {
+				e[0], e[1] = e[1], e[0]
+			}
+			used[e] = true
+			edges = append(edges, e)
+		}
+	}
+
+	// Additional edges drawn among the remaining candidate ones.
+	var candidates [][2]int
+	for u := 0; u < n; u++ {
+		for v := 0; v < n; v++ {
+			if u == v || ((g.Acyclic || !g.Directed) && u > v) {
+				continue
+			}
+			e := edge(u, v)
+			if !used[e] {
+				candidates = append(candidates, e)
+			}
+		}
+	}
+	for i := 0; len(edges) < m; i++ {
+		j := randint(r, i, len(candidates)-1)
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+		edges = append(edges, candidates[i])
+	}
+
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i][0] != edges[j][0] {
+			return edges[i][0] < edges[j][0]
+		}
+		return edges[i][1] < edges[j][1]
+	})
+	return Graph{n, edges}
+}
+
+// graph(n=min..max,m=min..max,connected,directed,weighted=desc,format=edges|adjacency)
+// tree(n=min..max,weighted=desc,format=edges|adjacency)
+// dag(n=min..max,m=min..max,connected,weighted=desc,format=edges|adjacency)
+func newGraphGenerator(p *Params) (RandomGenerator, error) {
+	g := GraphRandomGenerator{MinNodes: 1, MaxNodes: 10, MaxEdges: -1}
+	switch p.Name() {
+	case "tree":
+		g.Connected = true
+		g.Tree = true
+	case "dag":
+		g.Directed = true
+		g.Acyclic = true
+	}
+
+	var err error
+	if arg := p.Named("n"); arg != nil {
+		if g.MinNodes, g.MaxNodes, err = arg.Range(); err != nil {
+			return nil, err
+		}
+	}
+	if p.Name() != "tree" {
+		if arg := p.Named("m"); arg != nil {
+			if g.MinEdges, g.MaxEdges, err = arg.Range(); err != nil {
+				return nil, err
+			}
+		}
+		g.Connected = p.Flag("connected")
+	}
+	if p.Name() == "graph" {
+		g.Directed = p.Flag("directed")
+	}
+	if arg := p.Named("weighted"); arg != nil {
+		if g.Weights, err = arg.Generator(); err != nil {
+			return nil, err
+		}
+	}
+	if arg := p.Named("format"); arg != nil {
+		format, err := arg.Ident()
+		if err != nil || (format != "edges" && format != "adjacency") {
+			return nil, arg.Errorf("expected edges or adjacency")
+		}
+		g.Adjacency = format == "adjacency"
+	}
+
+	if g.MinNodes < 0 {
+		return nil, p.Errorf("number of vertices cannot be negative")
+	}
+	if g.MinEdges < 0 {
+		return nil, p.Errorf("number of edges cannot be negative")
+	}
+
+	// Both bounds on the number of edges grow with the number of vertices, so
+	// that the range of edges fits all of them when it fits both extremes.
+	if min, _ := g.edgeBounds(g.MaxNodes); g.MaxEdges >= 0 && g.MaxEdges < min {
+		return nil, p.Errorf("connected graphs with %d vertices need at least %d edges", g.MaxNodes, min)
+	}
+	if _, max := g.edgeBounds(g.MinNodes); g.MinEdges > max {
+		return nil, p.Errorf("graphs with %d vertices have at most %d edges", g.MinNodes, max)
+	}
+	return g, nil
+}
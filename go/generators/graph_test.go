@@ -0,0 +1,139 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"testing"
+)
+
+func TestGraphInvariants(t *testing.T) {
+	tests := []struct {
+		desc             string
+		minNodes         int
+		maxNodes         int
+		minEdges         int
+		maxEdges         int
+		connected, cycle bool
+	}{
+		{"graph(n=3..8,m=2..6)", 3, 8, 2, 6, false, true},
+		{"graph(n=1..10,m=0..9,connected)", 1, 10, 0, 9, true, true},
+		{"graph(n=4..6,m=5..30,directed)", 4, 6, 5, 30, false, true},
+		{"graph(n=0..5)", 0, 5, 0, 10, false, true},
+		{"tree(n=3..6)", 3, 6, 2, 5, true, false},
+		{"tree(n=0..20)", 0, 20, 0, 19, true, false},
+		{"dag(n=2..8,m=1..10)", 2, 8, 1, 10, false, false},
+		{"dag(n=2..8,connected)", 2, 8, 1, 16, true, false},
+	}
+
+	for _, test := range tests {
+		g := build(t, test.desc).(GraphRandomGenerator)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < samples; i++ {
+			graph := g.generateGraph(r)
+			n, m := graph.N, len(graph.Edges)
+			if n < test.minNodes || n > test.maxNodes {
+				t.Fatalf("%s: %d vertices", test.desc, n)
+			}
+			if m < test.minEdges || m > test.maxEdges {
+				t.Fatalf("%s: %d edges on %d vertices", test.desc, m, n)
+			}
+			if g.Tree && n > 0 && m != n-1 {
+				t.Fatalf("%s: tree with %d edges on %d vertices", test.desc, m, n)
+			}
+
+			seen := make(map[[2]int]bool)
+			for _, e := range graph.Edges {
+				if e[0] == e[1] || e[0] < 0 || e[1] >= n || seen[e] {
+					t.Fatalf("%s: invalid edge %v in %v", test.desc, e, graph.Edges)
+				}
+				seen[e] = true
+			}
+			if test.connected && components(graph) > 1 {
+				t.Fatalf("%s: graph is not connected: %v", test.desc, graph)
+			}
+			if !test.cycle && !g.Directed && components(graph) != n-m {
+				t.Fatalf("%s: graph has a cycle: %v", test.desc, graph)
+			}
+			if !test.cycle && g.Directed && hasCycle(graph) {
+				t.Fatalf("%s: graph has a cycle: %v", test.desc, graph)
+			}
+		}
+	}
+}
+
+func TestGraphErrors(t *testing.T) {
+	checkErrors(t, map[string]string{
+		"graph(n=1..10,m=20..30)":         "graphs with 1 vertices have at most 0 edges",
+		"graph(n=1..10,m=0..3,connected)": "connected graphs with 10 vertices need at least 9 edges",
+		"dag(n=2..5,m=0..3,connected)":    "connected graphs with 5 vertices need at least 4 edges",
+		"graph(n=-1..3)":                  "number of vertices cannot be negative",
+		"graph(n=1..3,format=matrix)":     "expected edges or adjacency",
+		"graph(n=2..3,m=4..5,directed)":   "graphs with 2 vertices have at most 2 edges",
+	})
+}
+
+// components counts the connected components of a graph, ignoring directions.
+func components(graph Graph) int {
+	parent := make([]int, graph.N)
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(u int) int {
+		if parent[u] != u {
+			parent[u] = find(parent[u])
+		}
+		return parent[u]
+	}
+	count := graph.N
+	for _, e := range graph.Edges {
+		if u, v := find(e[0]), find(e[1]); u != v {
+			parent[u] = v
+			count--
+		}
+	}
+	return count
+}
+
+func hasCycle(graph Graph) bool {
+	next := make([][]int, graph.N)
+	for _, e := range graph.Edges {
+		next[e[0]] = append(next[e[0]], e[1])
+	}
+	state := make([]int, graph.N)
+	var visit func(int) bool
+	visit = func(u int) bool {
+		state[u] = 1
+		for _, v := range next[u] {
+			if state[v] == 1 || (state[v] == 0 && visit(v)) {
+				return true
+			}
+		}
+		state[u] = 2
+		return false
+	}
+	for u := range next {
+		if state[u] == 0 && visit(u) {
+			return true
+		}
+	}
+	return false
+}
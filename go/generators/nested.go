@@ -0,0 +1,112 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// nested
+
+type NestedRandomGenerator struct {
+	MaxDepth  int
+	MaxWidth  int
+	MaxSize   int
+	Generator RandomGenerator
+}
+
+// Generates a random list whose elements are either values produced by another
+// generator or nested lists, with at most the given depth and width. The total
+// number of elements, counting both values and nested lists, is at most the
+// given size.
+func (g NestedRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	size := g.MaxSize
+	return g.generate(r, 1, &size)
+}
+
+func (g NestedRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// generate generates a list at the given depth, whose elements are taken from
+// the remaining size. The slots of all the elements are reserved before any
+// nested list is generated, so that the first ones do not use it all.
+func (g NestedRandomGenerator) generate(r *rand.Rand, depth int, size *int) Value {
+	length := randint(r, 0, g.MaxWidth)
+	if length > *size {
+		length = *size
+	}
+	*size -= length
+
+	data := make([]Value, length)
+	for i := range data {
+		if depth < g.MaxDepth && r.Intn(2) == 0 {
+			data[i] = g.generate(r, depth+1, size)
+		} else {
+			data[i] = GenerateValue(g.Generator, r)
+		}
+	}
+	return ArrayValue(data)
+}
+
+// nested(maxdepth,maxwidth)[desc]
+func newNestedGenerator(p *Params) (RandomGenerator, error) {
+	var depth, width int
+	if err := p.Scan(&depth, &width); err != nil {
+		return nil, err
+	}
+	if depth < 1 {
+		return nil, p.Arg(0).Errorf("depth must be positive")
+	}
+	if width < 0 {
+		return nil, p.Arg(1).Errorf("width cannot be negative")
+	}
+	size, err := maxSize(p)
+	if err != nil {
+		return nil, err
+	}
+	generator, err := p.Elem()
+	if err != nil {
+		return nil, err
+	}
+	return NestedRandomGenerator{depth, width, size, generator}, nil
+}
+
+// Default maximum total number of elements of nested lists.
+const defaultNestedSize = 100
+
+// maxSize reads the maximum total number of elements from the size modifier
+// of the nested generator, such as nested(5,3)[int(0,9)]|size(20).
+func maxSize(p *Params) (int, error) {
+	modifier := p.Modifier("size")
+	if modifier == nil {
+		return defaultNestedSize, nil
+	}
+
+	var size int
+	if err := modifier.Scan(&size); err != nil {
+		return 0, err
+	}
+	if size < 0 {
+		return 0, modifier.Arg(0).Errorf("size cannot be negative")
+	}
+	return size, nil
+}
@@ -0,0 +1,78 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"testing"
+)
+
+// shape computes the depth of nested lists, their maximum width and their
+// total number of elements, counting both values and nested lists.
+func shape(v Value) (depth int, width int, size int) {
+	width = len(v.Elems)
+	for _, elem := range v.Elems {
+		size++
+		if elem.Kind == ArrayKind {
+			d, w, s := shape(elem)
+			if d > depth {
+				depth = d
+			}
+			if w > width {
+				width = w
+			}
+			size += s
+		}
+	}
+	return depth + 1, width, size
+}
+
+func TestNested(t *testing.T) {
+	tests := []struct {
+		desc               string
+		depth, width, size int
+	}{
+		{"nested(1,5)[int(0,9)]", 1, 5, 5},
+		{"nested(4,3)[int(0,9)]", 4, 3, 100},
+		{"nested(6,6)[bool]|size(10)", 6, 6, 10},
+		{"nested(3,0)[bool]", 1, 0, 0},
+	}
+	for _, test := range tests {
+		deepest := 0
+		for _, value := range generate(t, test.desc) {
+			depth, width, size := shape(value)
+			if value.Kind != ArrayKind || depth > test.depth || width > test.width || size > test.size {
+				t.Errorf("%s: %s has depth %d, width %d and size %d", test.desc, value, depth, width, size)
+			}
+			if depth > deepest {
+				deepest = depth
+			}
+		}
+		if deepest != test.depth {
+			t.Errorf("%s: deepest list has depth %d, expected %d", test.desc, deepest, test.depth)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"nested(0,3)[bool]":          "depth must be positive",
+		"nested(2,-1)[bool]":         "width cannot be negative",
+		"nested(2,2)[bool]|size(-1)": "size cannot be negative",
+		"nested(2,2)":                "nested expects an element descriptor",
+	})
+}
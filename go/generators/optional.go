@@ -0,0 +1,173 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// null
+
+type NullRandomGenerator struct {
+}
+
+// Generates the missing value.
+func (g NullRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return NullValue()
+}
+
+func (g NullRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// null
+func newNullGenerator(p *Params) (RandomGenerator, error) {
+	if err := p.Scan(); err != nil {
+		return nil, err
+	}
+	return NullRandomGenerator{}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// optional
+
+type OptionalRandomGenerator struct {
+	P         float64
+	Generator RandomGenerator
+}
+
+// Generates the missing value with a given probability, and a value produced
+// by another generator otherwise.
+func (g OptionalRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	if r.Float64() < g.P {
+		return NullValue()
+	}
+	return GenerateValue(g.Generator, r)
+}
+
+func (g OptionalRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// optional(p)[desc]
+func newOptionalGenerator(p *Params) (RandomGenerator, error) {
+	var probability float64
+	if err := p.Scan(&probability); err != nil {
+		return nil, err
+	}
+	if probability < 0 || probability > 1 {
+		return nil, p.Arg(0).Errorf("probability must be comprised between 0 and 1")
+	}
+	generator, err := p.Elem()
+	if err != nil {
+		return nil, err
+	}
+	return OptionalRandomGenerator{probability, generator}, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// oneof
+
+type OneOfRandomGenerator struct {
+	Generators []RandomGenerator
+	Weights    []float64
+}
+
+// Generates a random value produced by one of several generators, chosen
+// uniformly or according to their weights, if any.
+func (g OneOfRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	return GenerateValue(g.Generators[choose(r, len(g.Generators), g.Weights)], r)
+}
+
+func (g OneOfRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+// alternatives lists the generators that can be chosen.
+func (g OneOfRandomGenerator) alternatives() []RandomGenerator {
+	var gens []RandomGenerator
+	for i, generator := range g.Generators {
+		if g.Weights == nil || g.Weights[i] > 0 {
+			gens = append(gens, generator)
+		}
+	}
+	return gens
+}
+
+// oneof(desc,...)
+func newOneOfGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() == 0 {
+		return nil, p.Errorf("oneof expects at least one descriptor")
+	}
+
+	generators := make([]RandomGenerator, p.Len())
+	weights := make([]float64, p.Len())
+	weighted := false
+	for i := range generators {
+		arg := p.Arg(i)
+		generator, err := arg.Generator()
+		if err != nil {
+			return nil, err
+		}
+		generators[i] = generator
+
+		weight, ok, err := arg.Weight()
+		if err != nil {
+			return nil, err
+		}
+		weights[i] = 1
+		if ok {
+			weights[i], weighted = weight, true
+		}
+	}
+	if !weighted {
+		return OneOfRandomGenerator{generators, nil}, nil
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	if total == 0 {
+		return nil, p.Errorf("oneof expects at least one positive weight")
+	}
+	return OneOfRandomGenerator{generators, weights}, nil
+}
+
+// choose draws an index among n, uniformly or according to the given weights.
+func choose(r *rand.Rand, n int, weights []float64) int {
+	if weights == nil {
+		return randint(r, 0, n-1)
+	}
+
+	total := 0.0
+	for _, weight := range weights {
+		total += weight
+	}
+	x := r.Float64() * total
+	for i, weight := range weights {
+		if x < weight {
+			return i
+		}
+		x -= weight
+	}
+	return n - 1
+}
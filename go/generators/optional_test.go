@@ -0,0 +1,75 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestOptionalAndOneOf(t *testing.T) {
+	const draws = 4000
+	tests := []struct {
+		desc  string
+		kinds map[Kind]float64 // expected frequency of each kind
+	}{
+		{"null", map[Kind]float64{NullKind: 1}},
+		{"optional(0)[int(0,9)]", map[Kind]float64{IntKind: 1}},
+		{"optional(1)[int(0,9)]", map[Kind]float64{NullKind: 1}},
+		{"optional(0.3)[str(0,3)]", map[Kind]float64{NullKind: 0.3, StringKind: 0.7}},
+		{"oneof(int(0,9),str(1,2),bool)", map[Kind]float64{IntKind: 1.0 / 3, StringKind: 1.0 / 3, BoolKind: 1.0 / 3}},
+		{"oneof(int(0,9):3,null:1,bool:0)", map[Kind]float64{IntKind: 0.75, NullKind: 0.25}},
+		{"array(5,5)[optional(0.5)[bool]]", map[Kind]float64{ArrayKind: 1}},
+	}
+	for _, test := range tests {
+		g := build(t, test.desc)
+		r := rand.New(rand.NewSource(1))
+		counts := make(map[Kind]float64)
+		for i := 0; i < draws; i++ {
+			counts[GenerateValue(g, r).Kind] += 1.0 / draws
+		}
+		for kind, count := range counts {
+			if want := test.kinds[kind]; math.Abs(count-want) > 0.05 {
+				t.Errorf("%s: kind %d drawn with frequency %.3f, expected %.3f", test.desc, kind, count, want)
+			}
+		}
+	}
+
+	// Null values are kept apart from the string "null" in every format.
+	for _, value := range generate(t, `array(4,4)[oneof(null,enum("null"))]`) {
+		for _, elem := range value.Elems {
+			if text := RenderJSON(elem); (elem.Kind == NullKind) != (text == "null") {
+				t.Errorf("%s rendered as %s", elem, text)
+			}
+		}
+	}
+	checkUnique(t, "array(3,3)[optional(0.5)[int(1,2)]]|unique", 3)
+
+	checkErrors(t, map[string]string{
+		"null(1)":              "null expects no arguments",
+		"optional(1.5)[bool]":  "probability must be comprised between 0 and 1",
+		"optional(0.5)":        "optional expects an element descriptor",
+		"oneof()":              "oneof expects at least one descriptor",
+		"oneof(bool:0,null:0)": "oneof expects at least one positive weight",
+		"oneof(bool,3)":        "expected a generator",
+		"array(7,7)[tuple(bool,optional(0.5)[bool])]|unique": "only 6 distinct values",
+	})
+}
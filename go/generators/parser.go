@@ -0,0 +1,710 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// DescriptorError describes an error located in a generator descriptor.
+type DescriptorError struct {
+	Col int
+	Msg string
+}
+
+func (e *DescriptorError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Msg, e.Col)
+}
+
+func errorf(col int, format string, args ...interface{}) error {
+	return &DescriptorError{col, fmt.Sprintf(format, args...)}
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Tokenizer
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenNumber
+	tokenString
+	tokenPunct
+	tokenRaw
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	col  int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of descriptor"
+	case tokenString:
+		return "string " + t.text
+	}
+	return "'" + t.text + "'"
+}
+
+type lexer struct {
+	src string
+	pos int
+}
+
+func (l *lexer) col(pos int) int {
+	return utf8.RuneCountInString(l.src[:pos]) + 1
+}
+
+func (l *lexer) peekRune(offset int) rune {
+	if l.pos+offset >= len(l.src) {
+		return 0
+	}
+	r, _ := utf8.DecodeRuneInString(l.src[l.pos+offset:])
+	return r
+}
+
+// next reads the next token of the descriptor, skipping whitespaces.
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+
+	start := l.pos
+	if start == len(l.src) {
+		return token{tokenEOF, "", l.col(start)}, nil
+	}
+
+	r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+	switch {
+	case r == '_' || unicode.IsLetter(r):
+		for l.pos < len(l.src) {
+			r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+			if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+				break
+			}
+			l.pos += size
+		}
+		return token{tokenIdent, l.src[start:l.pos], l.col(start)}, nil
+
+	case isDigit(r):
+		l.skipDigits()
+		if l.peekRune(0) == '.' && isDigit(l.peekRune(1)) {
+			l.pos++
+			l.skipDigits()
+		}
+		return token{tokenNumber, l.src[start:l.pos], l.col(start)}, nil
+
+	case r == '"' || r == '`':
+		l.pos++
+		for l.pos < len(l.src) && rune(l.src[l.pos]) != r {
+			if r == '"' && l.src[l.pos] == '\\' {
+				l.pos++
+			}
+			l.pos++
+		}
+		if l.pos >= len(l.src) {
+			return token{}, errorf(l.col(start), "unterminated string")
+		}
+		l.pos++
+		return token{tokenString, l.src[start:l.pos], l.col(start)}, nil
+
+	case r == '-' && l.peekRune(1) == '>':
+		l.pos += 2
+		return token{tokenPunct, "->", l.col(start)}, nil
+
+	case r == '.' && l.peekRune(1) == '.':
+		l.pos += 2
+		return token{tokenPunct, "..", l.col(start)}, nil
+
+	case isOperator(l.src[l.pos:]):
+		l.pos += 2
+		return token{tokenPunct, l.src[start:l.pos], l.col(start)}, nil
+
+	case isPunct(r):
+		l.pos += size
+		return token{tokenPunct, string(r), l.col(start)}, nil
+	}
+
+	return token{}, errorf(l.col(start), "unexpected character %q", r)
+}
+
+// raw reads the raw text of an argument, up to the next comma or closing
+// parenthesis outside of any nested parentheses or brackets, and without its
+// surrounding whitespaces. A string is read as such.
+func (l *lexer) raw() (token, error) {
+	for l.pos < len(l.src) {
+		r, size := utf8.DecodeRuneInString(l.src[l.pos:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		l.pos += size
+	}
+	if r := l.peekRune(0); r == '"' || r == '`' {
+		return l.next()
+	}
+
+	start, end, depth := l.pos, l.pos, 0
+	for ; l.pos < len(l.src); l.pos++ {
+		c := l.src[l.pos]
+		if depth == 0 && (c == ',' || c == ')') {
+			break
+		}
+		switch c {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		}
+		if c != ' ' && c != '\t' {
+			end = l.pos + 1
+		}
+	}
+	return token{tokenRaw, l.src[start:end], l.col(start)}, nil
+}
+
+func (l *lexer) skipDigits() {
+	for l.pos < len(l.src) && isDigit(rune(l.src[l.pos])) {
+		l.pos++
+	}
+}
+
+func isDigit(r rune) bool {
+	return '0' <= r && r <= '9'
+}
+
+func isPunct(r rune) bool {
+	switch r {
+	case '(', ')', '[', ']', '{', '}', ',', ':', '|', '=', '$', '+', '-', '*', '/', '%', '<', '>', '!':
+		return true
+	}
+	return false
+}
+
+// isOperator checks whether a source starts with a two-character comparison
+// or logical operator.
+func isOperator(src string) bool {
+	if len(src) < 2 {
+		return false
+	}
+	switch src[:2] {
+	case "<=", ">=", "==", "!=", "&&", "||":
+		return true
+	}
+	return false
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Parser
+
+// NodeKind identifies the kind of a node of a descriptor syntax tree.
+type NodeKind int
+
+const (
+	// GeneratorNode is a named generator, such as bool or int(0,9).
+	GeneratorNode NodeKind = iota
+	// NumberNode is a numeric literal.
+	NumberNode
+	// StringNode is a string literal, double-quoted or raw between backquotes.
+	StringNode
+	// RangeNode is a range of numbers, such as 5..10, whose bounds are its arguments.
+	RangeNode
+	// RefNode is a reference to another argument, such as $1, whose number is its value.
+	RefNode
+	// OperatorNode is an arithmetic, comparison or logical operator, such as - in
+	// len($1)-1, applied to its arguments.
+	OperatorNode
+)
+
+// Node is a node of the syntax tree of a generator descriptor. Generator nodes
+// hold the name of the generator in Value, together with their arguments, the
+// descriptor of their elements (or keys and values, for maps), their named
+// fields and their modifiers, if any. Literal nodes hold their value. Nodes
+// used as arguments may have a name, such as n in graph(n=5..10), and a weight,
+// such as 3 in enum(a:3,b:1).
+type Node struct {
+	Kind      NodeKind
+	Name      string
+	Value     string
+	Args      []*Node
+	Elem      *Node
+	ElemValue *Node
+	Fields    []*Field
+	Modifiers []*Node
+	Weight    *Node
+	Col       int
+}
+
+// Field is a named field of a generator descriptor, such as age:int(0,99).
+type Field struct {
+	Name string
+	Desc *Node
+	Col  int
+}
+
+type parser struct {
+	lex *lexer
+	tok token
+}
+
+// Parse parses a generator descriptor such as array(1,5)[int(0,9)] into its
+// syntax tree.
+func Parse(desc string) (*Node, error) {
+	p := &parser{lex: &lexer{src: desc}}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	node, err := p.parseDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenEOF {
+		return nil, p.unexpected()
+	}
+	return node, nil
+}
+
+func (p *parser) advance() error {
+	tok, err := p.lex.next()
+	if err != nil {
+		return err
+	}
+	p.tok = tok
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	return errorf(p.tok.col, "unexpected %s", p.tok)
+}
+
+func (p *parser) is(punct string) bool {
+	return p.tok.kind == tokenPunct && p.tok.text == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.is(punct) {
+		return p.unexpected()
+	}
+	return p.advance()
+}
+
+// descriptor := IDENT [ args ] [ elem ] [ fields ] { '|' IDENT [ args ] }
+// elem       := '[' descriptor [ '->' descriptor ] ']'
+// fields     := '{' [ field { ',' field } ] '}'
+func (p *parser) parseDescriptor() (*Node, error) {
+	if p.tok.kind != tokenIdent {
+		return nil, p.unexpected()
+	}
+	node := &Node{Kind: GeneratorNode, Value: p.tok.text, Col: p.tok.col}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+
+	if p.is("(") {
+		parseArgs := p.parseArgs
+		if rawArgs(node.Value) {
+			parseArgs = p.parseRawArgs
+		}
+		args, err := parseArgs()
+		if err != nil {
+			return nil, err
+		}
+		node.Args = args
+	}
+
+	if p.is("[") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		elem, err := p.parseDescriptor()
+		if err != nil {
+			return nil, err
+		}
+		node.Elem = elem
+		if p.is("->") {
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			value, err := p.parseDescriptor()
+			if err != nil {
+				return nil, err
+			}
+			node.ElemValue = value
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+
+	if p.is("{") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		node.Fields = []*Field{}
+		for !p.is("}") {
+			if len(node.Fields) > 0 {
+				if err := p.expect(","); err != nil {
+					return nil, err
+				}
+			}
+			field, err := p.parseField()
+			if err != nil {
+				return nil, err
+			}
+			node.Fields = append(node.Fields, field)
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+
+	for p.is("|") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenIdent {
+			return nil, p.unexpected()
+		}
+		modifier := &Node{Kind: GeneratorNode, Value: p.tok.text, Col: p.tok.col}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.is("(") {
+			args, err := p.parseArgs()
+			if err != nil {
+				return nil, err
+			}
+			modifier.Args = args
+		}
+		node.Modifiers = append(node.Modifiers, modifier)
+	}
+
+	return node, nil
+}
+
+// field := IDENT ':' descriptor
+func (p *parser) parseField() (*Field, error) {
+	if p.tok.kind != tokenIdent {
+		return nil, p.unexpected()
+	}
+	field := &Field{Name: p.tok.text, Col: p.tok.col}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	if err := p.expect(":"); err != nil {
+		return nil, err
+	}
+
+	desc, err := p.parseDescriptor()
+	if err != nil {
+		return nil, err
+	}
+	field.Desc = desc
+	return field, nil
+}
+
+// args := '(' [ arg { ',' arg } ] ')'
+func (p *parser) parseArgs() ([]*Node, error) {
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+
+	args := []*Node{}
+	for !p.is(")") {
+		if len(args) > 0 {
+			if err := p.expect(","); err != nil {
+				return nil, err
+			}
+		}
+		arg, err := p.parseArg()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	return args, p.advance()
+}
+
+// rawArgs := '(' [ rawArg { ',' rawArg } ] ')'
+// rawArg  := ( STRING | TEXT ) [ ':' NUMBER ]
+//
+// Raw arguments are taken as text, such as + and a b in enum(+,a b), except for
+// strings, which are needed for values with commas or surrounding whitespaces.
+func (p *parser) parseRawArgs() ([]*Node, error) {
+	args := []*Node{}
+	for {
+		tok, err := p.lex.raw()
+		if err != nil {
+			return nil, err
+		}
+
+		var node *Node
+		switch {
+		case tok.kind == tokenString:
+			value, err := strconv.Unquote(tok.text)
+			if err != nil {
+				return nil, errorf(tok.col, "invalid string %s", tok.text)
+			}
+			node = &Node{Kind: StringNode, Value: value, Col: tok.col}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if p.is(":") {
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+				if p.tok.kind != tokenNumber {
+					return nil, p.unexpected()
+				}
+				node.Weight = &Node{Kind: NumberNode, Value: p.tok.text, Col: p.tok.col}
+				if err := p.advance(); err != nil {
+					return nil, err
+				}
+			}
+		case tok.text == "":
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+			if len(args) == 0 && p.is(")") {
+				return args, p.advance()
+			}
+			return nil, errorf(tok.col, "expected a value, quote it as \"\" if empty")
+		default:
+			node = &Node{Kind: StringNode, Value: tok.text, Col: tok.col}
+			if i := strings.LastIndexByte(tok.text, ':'); i > 0 && isNumber(tok.text[i+1:]) {
+				node.Value = strings.TrimRight(tok.text[:i], " \t")
+				node.Weight = &Node{Kind: NumberNode, Value: tok.text[i+1:], Col: tok.col + utf8.RuneCountInString(tok.text[:i+1])}
+			}
+			if err := p.advance(); err != nil {
+				return nil, err
+			}
+		}
+		args = append(args, node)
+
+		if p.is(")") {
+			return args, p.advance()
+		}
+		if !p.is(",") {
+			return nil, p.unexpected()
+		}
+	}
+}
+
+// isNumber checks whether a text is a number as read by the tokenizer.
+func isNumber(text string) bool {
+	l := &lexer{src: text}
+	tok, err := l.next()
+	return err == nil && tok.kind == tokenNumber && l.pos == len(text)
+}
+
+// arg := [ IDENT '=' ] value [ ':' number ]
+func (p *parser) parseArg() (*Node, error) {
+	node, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.is("=") {
+		if node.Kind != GeneratorNode || node.Args != nil || node.Elem != nil || node.Fields != nil || node.Modifiers != nil {
+			return nil, p.unexpected()
+		}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		name := node.Value
+		if node, err = p.parseValue(); err != nil {
+			return nil, err
+		}
+		node.Name = name
+	}
+
+	if p.is(":") {
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenNumber {
+			return nil, p.unexpected()
+		}
+		node.Weight = &Node{Kind: NumberNode, Value: p.tok.text, Col: p.tok.col}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+	}
+	return node, nil
+}
+
+// value := STRING | expr [ '..' expr ]
+func (p *parser) parseValue() (*Node, error) {
+	if p.tok.kind == tokenString {
+		value, err := strconv.Unquote(p.tok.text)
+		if err != nil {
+			return nil, errorf(p.tok.col, "invalid string %s", p.tok.text)
+		}
+		node := &Node{Kind: StringNode, Value: value, Col: p.tok.col}
+		return node, p.advance()
+	}
+
+	node, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	if !p.is("..") {
+		return node, nil
+	}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	max, err := p.parseExpr()
+	if err != nil {
+		return nil, err
+	}
+	return &Node{Kind: RangeNode, Args: []*Node{node, max}, Col: node.Col}, nil
+}
+
+// expr := conjunction { '||' conjunction }
+func (p *parser) parseExpr() (*Node, error) {
+	return p.parseBinary(p.parseConjunction, "||")
+}
+
+// conjunction := comparison { '&&' comparison }
+func (p *parser) parseConjunction() (*Node, error) {
+	return p.parseBinary(p.parseComparison, "&&")
+}
+
+// comparison := sum [ ( '<' | '<=' | '>' | '>=' | '==' | '!=' ) sum ]
+func (p *parser) parseComparison() (*Node, error) {
+	node, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.kind != tokenPunct || !contains(comparisons, p.tok.text) {
+		return node, nil
+	}
+
+	op := &Node{Kind: OperatorNode, Value: p.tok.text, Col: p.tok.col}
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	op.Args = []*Node{node, right}
+	return op, nil
+}
+
+var comparisons = []string{"<", "<=", ">", ">=", "==", "!="}
+
+// sum := term { ( '+' | '-' ) term }
+func (p *parser) parseSum() (*Node, error) {
+	return p.parseBinary(p.parseTerm, "+", "-")
+}
+
+// term := unary { ( '*' | '/' | '%' ) unary }
+func (p *parser) parseTerm() (*Node, error) {
+	return p.parseBinary(p.parseUnary, "*", "/", "%")
+}
+
+func (p *parser) parseBinary(operand func() (*Node, error), operators ...string) (*Node, error) {
+	node, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.tok.kind == tokenPunct && contains(operators, p.tok.text) {
+		op := &Node{Kind: OperatorNode, Value: p.tok.text, Col: p.tok.col}
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		op.Args = []*Node{node, right}
+		node = op
+	}
+	return node, nil
+}
+
+// unary := ( '-' | '!' ) unary | primary
+func (p *parser) parseUnary() (*Node, error) {
+	if !p.is("-") && !p.is("!") {
+		return p.parsePrimary()
+	}
+
+	op, col := p.tok.text, p.tok.col
+	if err := p.advance(); err != nil {
+		return nil, err
+	}
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	if op == "-" && operand.Kind == NumberNode && operand.Value[0] != '-' {
+		operand.Value = "-" + operand.Value
+		operand.Col = col
+		return operand, nil
+	}
+	return &Node{Kind: OperatorNode, Value: op, Args: []*Node{operand}, Col: col}, nil
+}
+
+// primary := NUMBER | '$' NUMBER | '(' expr ')' | descriptor
+func (p *parser) parsePrimary() (*Node, error) {
+	switch {
+	case p.tok.kind == tokenNumber:
+		node := &Node{Kind: NumberNode, Value: p.tok.text, Col: p.tok.col}
+		return node, p.advance()
+
+	case p.is("$"):
+		col := p.tok.col
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokenNumber {
+			return nil, p.unexpected()
+		}
+		node := &Node{Kind: RefNode, Value: p.tok.text, Col: col}
+		return node, p.advance()
+
+	case p.is("("):
+		if err := p.advance(); err != nil {
+			return nil, err
+		}
+		node, err := p.parseExpr()
+		if err != nil {
+			return nil, err
+		}
+		return node, p.expect(")")
+	}
+
+	return p.parseDescriptor()
+}
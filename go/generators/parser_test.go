@@ -0,0 +1,123 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	node, err := Parse("map(1..3, 5)[str(1,2,`ab`)->array(0,n=2)[int(-1,len($1)*2)]]{a:bool}|sorted|x(1.5:2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Kind != GeneratorNode || node.Value != "map" || len(node.Args) != 2 || len(node.Modifiers) != 2 {
+		t.Fatalf("unexpected map node %+v", node)
+	}
+	if r := node.Args[0]; r.Kind != RangeNode || r.Args[0].Value != "1" || r.Args[1].Value != "3" {
+		t.Errorf("unexpected range node %+v", r)
+	}
+	if s := node.Elem.Args[2]; s.Kind != StringNode || s.Value != "ab" || s.Col != 22 {
+		t.Errorf("unexpected string node %+v", s)
+	}
+	array := node.ElemValue
+	if array.Value != "array" || array.Args[1].Name != "n" || array.Args[1].Value != "2" {
+		t.Errorf("unexpected array node %+v", array)
+	}
+	max := array.Elem.Args[1]
+	if max.Kind != OperatorNode || max.Value != "*" || max.Args[0].Value != "len" || max.Args[0].Args[0].Kind != RefNode {
+		t.Errorf("unexpected expression node %+v", max)
+	}
+	if min := array.Elem.Args[0]; min.Kind != NumberNode || min.Value != "-1" {
+		t.Errorf("unexpected negative number node %+v", min)
+	}
+	sorted := node.Modifiers[0]
+	if sorted.Value != "sorted" || len(sorted.Args) != 0 || len(node.Fields) != 1 || node.Fields[0].Name != "a" {
+		t.Errorf("unexpected modifier node %+v", sorted)
+	}
+	if x := node.Modifiers[1]; x.Args[0].Value != "1.5" || x.Args[0].Weight.Value != "2" {
+		t.Errorf("unexpected weighted argument %+v", x.Args[0])
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"":                    "unexpected end of descriptor at column 1",
+		"int(0,9":             "unexpected end of descriptor at column 8",
+		"int(0;9)":            "unexpected character ';' at column 6",
+		"str(1,2,\"ab)":       "unterminated string at column 9",
+		"array(1,2)[int(0,9)": "unexpected end of descriptor at column 20",
+		"int(0,9) bool":       "unexpected 'bool' at column 10",
+		"int(0,9)|":           "unexpected end of descriptor at column 10",
+		"int(a=b=c)":          "unexpected '=' at column 8",
+		"int(1:x)":            "unexpected 'x' at column 7",
+		"map(1,2)[int->]":     "unexpected ']' at column 15",
+	}
+	for desc, msg := range tests {
+		if _, err := Parse(desc); err == nil || err.Error() != msg {
+			t.Errorf("%q: got error %v, expected %q", desc, err, msg)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"foo(1)":                "unknown generator 'foo' at column 1",
+		"int(0,9)|sorted":       "unknown modifier 'sorted' for int at column 10",
+		"int(0,9,3)":            "int expects 2 arguments at column 1",
+		"bool(1)":               "bool expects no arguments",
+		"int(0,x)":              "expected an integer at column 7",
+		"array(3,1)[bool]":      "invalid bounds, 3 is greater than 1",
+		"array(1,2)":            "array expects an element descriptor",
+		"bool[int(0,1)]":        "unexpected element descriptor for bool at column 6",
+		"record{a:bool,a:bool}": "duplicate field 'a' at column 15",
+		"tuple(1)":              "expected a generator at column 7",
+	})
+}
+
+func TestEnumValues(t *testing.T) {
+	tests := []struct {
+		desc    string
+		values  []string
+		weights []float64
+	}{
+		{"enum(+,-,*,/)", []string{"+", "-", "*", "/"}, nil},
+		{"enum(a b,c)", []string{"a b", "c"}, nil},
+		{"enum( a , b )", []string{"a", "b"}, nil},
+		{"enum(f(x,y),[1,2])", []string{"f(x,y)", "[1,2]"}, nil},
+		{"enum(a:3,b c:1)", []string{"a", "b c"}, []float64{3, 1}},
+		{"enum(\"a,b\",\" c\":2)", []string{"a,b", " c"}, []float64{1, 2}},
+		{"enum(http://pythia,1.5)", []string{"http://pythia", "1.5"}, nil},
+		{"enum(==,!=,<=)", []string{"==", "!=", "<="}, nil},
+	}
+	for _, test := range tests {
+		enum := build(t, test.desc).(EnumRandomGenerator)
+		if !reflect.DeepEqual(enum.Values, test.values) || !reflect.DeepEqual(enum.Weights, test.weights) {
+			t.Errorf("%s: got %q %v, expected %q %v", test.desc, enum.Values, enum.Weights, test.values, test.weights)
+		}
+	}
+
+	checkErrors(t, map[string]string{
+		"enum()":       "at least one value",
+		"enum(a,,b)":   "expected a value",
+		"enum(a,b":     "unexpected end of descriptor",
+		"enum(\"a\"b)": "unexpected 'b'",
+		"enum(a:0)":    "at least one positive weight",
+	})
+}
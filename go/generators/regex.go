@@ -0,0 +1,210 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// regex
+
+// Default maximum number of repetitions for unbounded operators (*, + and {n,}).
+const defaultMaxRepeat = 8
+
+// Printable ASCII characters, used for . and classes reaching the end of the
+// Unicode range, such as negated classes.
+var printableRange = []rune{' ', '~'}
+
+type RegexRandomGenerator struct {
+	Regexp    *syntax.Regexp
+	MaxRepeat int
+}
+
+// Generates a random string matching a regular expression.
+func (g RegexRandomGenerator) GenerateValue(r *rand.Rand) Value {
+	var sb strings.Builder
+	g.generate(r, g.Regexp, &sb)
+	return StringValue(sb.String())
+}
+
+func (g RegexRandomGenerator) Generate(r *rand.Rand) string {
+	return g.GenerateValue(r).String()
+}
+
+func (g RegexRandomGenerator) generate(r *rand.Rand, re *syntax.Regexp, sb *strings.Builder) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if re.Flags&syntax.FoldCase != 0 && r.Intn(2) == 0 {
+				c = unicode.SimpleFold(c)
+			}
+			sb.WriteRune(c)
+		}
+
+	case syntax.OpCharClass:
+		sb.WriteRune(randrune(r, classRanges(re.Rune)))
+
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(randrune(r, printableRange))
+
+	case syntax.OpCapture:
+		g.generate(r, re.Sub[0], sb)
+
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, g.MaxRepeat
+		case syntax.OpPlus:
+			min, max = 1, g.MaxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max == -1 {
+			max = min + g.MaxRepeat
+		}
+		if matchesNothing(re.Sub[0]) {
+			max = min
+		}
+		for i := randint(r, min, max); i > 0; i-- {
+			g.generate(r, re.Sub[0], sb)
+		}
+
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			g.generate(r, sub, sb)
+		}
+
+	case syntax.OpAlternate:
+		var subs []*syntax.Regexp
+		for _, sub := range re.Sub {
+			if !matchesNothing(sub) {
+				subs = append(subs, sub)
+			}
+		}
+		g.generate(r, subs[randint(r, 0, len(subs)-1)], sb)
+	}
+
+	// Other operators, such as anchors and word boundaries, match the empty string.
+}
+
+// regex(pattern[,maxrepeat])
+func newRegexGenerator(p *Params) (RandomGenerator, error) {
+	if p.Len() < 1 || p.Len() > 2 {
+		return nil, p.Errorf("regex expects a pattern and an optional maximum repetition")
+	}
+	pattern, err := p.Arg(0).Str()
+	if err != nil {
+		return nil, err
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, p.Arg(0).Errorf("invalid regular expression, %s", err)
+	}
+	if matchesNothing(re) {
+		return nil, p.Arg(0).Errorf("regular expression matches nothing")
+	}
+
+	maxRepeat := defaultMaxRepeat
+	if p.Len() == 2 {
+		arg := p.Arg(1)
+		if maxRepeat, err = arg.Int(); err != nil || maxRepeat < 0 {
+			return nil, arg.Errorf("expected a non-negative integer")
+		}
+	}
+	return RegexRandomGenerator{re, maxRepeat}, nil
+}
+
+// classRanges restricts the ranges of a class reaching the end of the Unicode
+// range to printable ASCII characters, when it contains some.
+func classRanges(ranges []rune) []rune {
+	if ranges[len(ranges)-1] != unicode.MaxRune {
+		return ranges
+	}
+
+	var printable []rune
+	for i := 0; i < len(ranges); i += 2 {
+		lo, hi := ranges[i], ranges[i+1]
+		if lo < printableRange[0] {
+			lo = printableRange[0]
+		}
+		if hi > printableRange[1] {
+			hi = printableRange[1]
+		}
+		if lo <= hi {
+			printable = append(printable, lo, hi)
+		}
+	}
+	if len(printable) == 0 {
+		return ranges
+	}
+	return printable
+}
+
+// randrune picks a random character from a list of ranges given as pairs of
+// inclusive bounds.
+func randrune(r *rand.Rand, ranges []rune) rune {
+	total := 0
+	for i := 0; i < len(ranges); i += 2 {
+		total += int(ranges[i+1]-ranges[i]) + 1
+	}
+
+	n := randint(r, 0, total-1)
+	for i := 0; i < len(ranges); i += 2 {
+		size := int(ranges[i+1]-ranges[i]) + 1
+		if n < size {
+			return ranges[i] + rune(n)
+		}
+		n -= size
+	}
+	return ranges[0]
+}
+
+// matchesNothing checks whether a regular expression cannot match any string.
+func matchesNothing(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpNoMatch:
+		return true
+	case syntax.OpCharClass:
+		return len(re.Rune) == 0
+	case syntax.OpCapture, syntax.OpPlus:
+		return matchesNothing(re.Sub[0])
+	case syntax.OpRepeat:
+		return re.Min > 0 && matchesNothing(re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if matchesNothing(sub) {
+				return true
+			}
+		}
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !matchesNothing(sub) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
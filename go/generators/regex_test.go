@@ -0,0 +1,69 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestRegex(t *testing.T) {
+	tests := []struct {
+		pattern string
+		maxLen  int
+	}{
+		{`\d{3}-\d{4}`, 8},
+		{`[A-Z][a-z]{2,5}`, 6},
+		{`(?i)abc`, 3},
+		{`(foo|bar)+`, 24},
+		{`[^a-z]{3}`, 3},
+		{`x*y?`, 9},
+		{`^a{2,}$`, 10},
+		{`.[\p{Greek}]`, 2},
+		{`a|[^\x00-\x{10FFFF}]`, 1},
+	}
+	for _, test := range tests {
+		re := regexp.MustCompile(`^(?:` + test.pattern + `)$`)
+		desc := "regex(`" + test.pattern + "`)"
+		for _, value := range generate(t, desc) {
+			if value.Kind != StringKind || !re.MatchString(value.Text) {
+				t.Errorf("%s: %q does not match", desc, value.Text)
+			}
+			if n := len([]rune(value.Text)); n > test.maxLen {
+				t.Errorf("%s: %q is longer than %d", desc, value.Text, test.maxLen)
+			}
+		}
+	}
+	for _, value := range generate(t, "regex(`a+`,2)") {
+		if value.Text != "a" && value.Text != "aa" {
+			t.Errorf("regex(`a+`,2): got %q", value.Text)
+		}
+	}
+	checkUnique(t, "array(2,2)[regex(`[ab]`)]", 2)
+
+	checkErrors(t, map[string]string{
+		"regex(`a(b`)":                     "invalid regular expression",
+		"regex(`[^\\x00-\\x{10FFFF}]`)":    "regular expression matches nothing",
+		"regex(`a`,-1)":                    "expected a non-negative integer",
+		"regex(a)":                         "expected a string",
+		"regex":                            "regex expects a pattern",
+		"array(2,3)[regex(`[ab]`)]|unique": "cannot guarantee distinct values",
+	})
+}
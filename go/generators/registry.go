@@ -0,0 +1,496 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math/big"
+	"strconv"
+	"sync"
+)
+
+////////////////////////////////////////////////////////////////////////////////
+// Registry
+
+// Factory builds a generator from the parameters of a descriptor.
+type Factory func(p *Params) (RandomGenerator, error)
+
+// registration is a generator made available in descriptors.
+type registration struct {
+	factory Factory
+	raw     bool // positional arguments read as raw text
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+func init() {
+	Register("int", newIntGenerator)
+	Register("bool", newBoolGenerator)
+	Register("float", newFloatGenerator)
+	Register("str", newStringGenerator)
+	RegisterRaw("enum", newEnumGenerator)
+	Register("array", newArrayGenerator)
+	Register("permutation", newPermutationGenerator)
+	Register("map", newMapGenerator)
+	Register("tuple", newTupleGenerator)
+	Register("record", newRecordGenerator)
+	Register("regex", newRegexGenerator)
+	Register("normal", newNormalGenerator)
+	Register("exponential", newExponentialGenerator)
+	Register("poisson", newPoissonGenerator)
+	Register("geometric", newGeometricGenerator)
+	Register("bigint", newBigIntGenerator)
+	Register("digits", newDigitsGenerator)
+	Register("null", newNullGenerator)
+	Register("optional", newOptionalGenerator)
+	Register("oneof", newOneOfGenerator)
+	Register("nested", newNestedGenerator)
+	Register("grammar", newGrammarGenerator)
+	Register("pick", newPickGenerator)
+	Register("sentence", newSentenceGenerator)
+	Register("point", newPointGenerator)
+	Register("segment", newSegmentGenerator)
+	Register("polygon", newPolygonGenerator)
+	Register("graph", newGraphGenerator)
+	Register("tree", newGraphGenerator)
+	Register("dag", newGraphGenerator)
+}
+
+// Register makes a generator available in descriptors under the given name.
+// It panics if the factory is nil, if the name is already registered or if it
+// is the name of a function that can be used in expressions.
+func Register(name string, factory Factory) {
+	register(name, registration{factory, false})
+}
+
+// RegisterRaw makes a generator available in descriptors under the given name,
+// like Register, but its positional arguments are read as raw text, such as
+// the values of enum(red, dark blue, "a, b"), which may be quoted when they
+// contain commas or parentheses, and may have weights.
+func RegisterRaw(name string, factory Factory) {
+	register(name, registration{factory, true})
+}
+
+func register(name string, r registration) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if r.factory == nil {
+		panic("generators: Register factory is nil")
+	}
+	if _, ok := registry[name]; ok {
+		panic("generators: Register called twice for " + name)
+	}
+	if _, ok := functions[name]; ok {
+		panic("generators: Register called with the reserved name " + name)
+	}
+	registry[name] = r
+}
+
+// rawArgs checks whether the positional arguments of a generator are read as
+// raw text.
+func rawArgs(name string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	return registry[name].raw
+}
+
+func buildGenerator(node *Node) (RandomGenerator, error) {
+	if node.Kind != GeneratorNode {
+		return nil, errorf(node.Col, "expected a generator")
+	}
+
+	registryMu.RLock()
+	registered, ok := registry[node.Value]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, errorf(node.Col, "unknown generator '%s'", node.Value)
+	}
+
+	p := newParams(node)
+	generator, err := registered.factory(p)
+	if err != nil {
+		return nil, err
+	}
+	if err := p.checkUsed(); err != nil {
+		return nil, err
+	}
+	return generator, nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Parameters
+
+// Params gives typed access to the parameters of a descriptor: its positional
+// and named arguments, the descriptors of its elements, its fields and its
+// modifiers. Errors are located in the descriptor, and parameters that are not
+// used by the factory are reported as unexpected.
+type Params struct {
+	node          *Node
+	args          []*Arg
+	elemUsed      bool
+	elemValueUsed bool
+	fieldsUsed    bool
+	modifiers     map[*Node]*Params
+}
+
+// Arg is an argument of a descriptor, such as 5 in int(5,10) or n=5..10 in
+// graph(n=5..10).
+type Arg struct {
+	node       *Node
+	params     *Params
+	index      int
+	used       bool
+	weightUsed bool
+}
+
+func newParams(node *Node) *Params {
+	p := &Params{node: node, modifiers: make(map[*Node]*Params)}
+	for _, arg := range node.Args {
+		p.args = append(p.args, &Arg{node: arg, params: p})
+	}
+	return p
+}
+
+// Name returns the name of the generator.
+func (p *Params) Name() string {
+	return p.node.Value
+}
+
+// Errorf returns an error located at the descriptor.
+func (p *Params) Errorf(format string, args ...interface{}) error {
+	return errorf(p.node.Col, format, args...)
+}
+
+func (p *Params) positional() []*Arg {
+	var args []*Arg
+	for _, arg := range p.args {
+		if arg.node.Name == "" {
+			args = append(args, arg)
+		}
+	}
+	return args
+}
+
+// Len returns the number of positional arguments.
+func (p *Params) Len() int {
+	return len(p.positional())
+}
+
+// Arg returns the i-th positional argument. Reading a missing argument fails.
+func (p *Params) Arg(i int) *Arg {
+	if args := p.positional(); i < len(args) {
+		args[i].used = true
+		return args[i]
+	}
+	return &Arg{params: p, index: i}
+}
+
+// Named returns the argument with the given name, or nil if there is none.
+func (p *Params) Named(name string) *Arg {
+	for _, arg := range p.args {
+		if arg.node.Name == name {
+			arg.used = true
+			return arg
+		}
+	}
+	return nil
+}
+
+// Flag checks whether a positional argument is the given identifier, such as
+// connected in graph(n=5, connected).
+func (p *Params) Flag(name string) bool {
+	for _, arg := range p.positional() {
+		if arg.isIdent() && arg.node.Value == name {
+			arg.used = true
+			return true
+		}
+	}
+	return false
+}
+
+// Scan reads exactly len(dest) positional arguments into the given pointers,
+// which can be of type *int, *float64, *big.Int, *string (for string literals)
+// or *RandomGenerator (for descriptors). Other types are reported as an error.
+func (p *Params) Scan(dest ...interface{}) error {
+	if len(dest) == 0 && p.Len() > 0 {
+		return p.Errorf("%s expects no arguments", p.Name())
+	}
+	if p.Len() != len(dest) {
+		return p.Errorf("%s expects %d arguments", p.Name(), len(dest))
+	}
+
+	for i, d := range dest {
+		var err error
+		arg := p.Arg(i)
+		switch d := d.(type) {
+		case *int:
+			*d, err = arg.Int()
+		case *float64:
+			*d, err = arg.Float()
+		case *big.Int:
+			var value *big.Int
+			if value, err = arg.BigInt(); err == nil {
+				d.Set(value)
+			}
+		case *string:
+			*d, err = arg.Str()
+		case *RandomGenerator:
+			*d, err = arg.Generator()
+		default:
+			err = arg.Errorf("cannot read argument into %T", d)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Elem builds the generator for the element descriptor, such as int(0,9) in
+// array(1,5)[int(0,9)], or for the key descriptor of a map.
+func (p *Params) Elem() (RandomGenerator, error) {
+	p.elemUsed = true
+	if p.node.Elem == nil {
+		return nil, p.Errorf("%s expects an element descriptor", p.Name())
+	}
+	return buildGenerator(p.node.Elem)
+}
+
+// ElemValue builds the generator for the value descriptor, such as int(0,9)
+// in map(1,5)[str(1,3)->int(0,9)].
+func (p *Params) ElemValue() (RandomGenerator, error) {
+	p.elemValueUsed = true
+	if p.node.ElemValue == nil {
+		return nil, p.Errorf("%s expects a value descriptor", p.Name())
+	}
+	return buildGenerator(p.node.ElemValue)
+}
+
+// Fields builds the generators for the named fields, such as name and age in
+// record{name:str(1,8), age:int(0,99)}, whose names must be distinct.
+func (p *Params) Fields() ([]string, []RandomGenerator, error) {
+	p.fieldsUsed = true
+	if len(p.node.Fields) == 0 {
+		return nil, nil, p.Errorf("%s expects at least one field", p.Name())
+	}
+
+	names := make([]string, len(p.node.Fields))
+	generators := make([]RandomGenerator, len(p.node.Fields))
+	for i, field := range p.node.Fields {
+		if contains(names[:i], field.Name) {
+			return nil, nil, errorf(field.Col, "duplicate field '%s'", field.Name)
+		}
+		generator, err := buildGenerator(field.Desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		names[i] = field.Name
+		generators[i] = generator
+	}
+	return names, generators, nil
+}
+
+// Modifier returns the parameters of the last modifier with the given name,
+// such as sorted in array(1,5)[int(0,9)]|sorted, or nil if there is none.
+func (p *Params) Modifier(name string) *Params {
+	var found *Params
+	for _, modifier := range p.node.Modifiers {
+		if modifier.Value == name {
+			found = newParams(modifier)
+			p.modifiers[modifier] = found
+		}
+	}
+	return found
+}
+
+// checkUsed reports the first parameter that was not used by the factory.
+func (p *Params) checkUsed() error {
+	for _, arg := range p.args {
+		if !arg.used {
+			return errorf(arg.node.Col, "unexpected argument for %s", p.Name())
+		}
+		if arg.node.Weight != nil && !arg.weightUsed {
+			return errorf(arg.node.Weight.Col, "unexpected weight for %s", p.Name())
+		}
+	}
+	if p.node.Elem != nil && !p.elemUsed {
+		return errorf(p.node.Elem.Col, "unexpected element descriptor for %s", p.Name())
+	}
+	if p.node.ElemValue != nil && !p.elemValueUsed {
+		return errorf(p.node.ElemValue.Col, "unexpected value descriptor for %s", p.Name())
+	}
+	if p.node.Fields != nil && !p.fieldsUsed {
+		return p.Errorf("unexpected fields for %s", p.Name())
+	}
+	for _, modifier := range p.node.Modifiers {
+		params, ok := p.modifiers[modifier]
+		if !ok {
+			return errorf(modifier.Col, "unknown modifier '%s' for %s", modifier.Value, p.Name())
+		}
+		if err := params.checkUsed(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Arguments
+
+// Col returns the column of the argument in the descriptor.
+func (a *Arg) Col() int {
+	if a.node == nil {
+		return a.params.node.Col
+	}
+	return a.node.Col
+}
+
+// Errorf returns an error located at the argument.
+func (a *Arg) Errorf(format string, args ...interface{}) error {
+	return errorf(a.Col(), format, args...)
+}
+
+func (a *Arg) missing() error {
+	return a.params.Errorf("%s expects at least %d arguments", a.params.Name(), a.index+1)
+}
+
+func (a *Arg) isIdent() bool {
+	return a.node.Kind == GeneratorNode && a.node.Args == nil && a.node.Elem == nil && a.node.Fields == nil && a.node.Modifiers == nil
+}
+
+// Int reads an integer.
+func (a *Arg) Int() (int, error) {
+	if a.node == nil {
+		return 0, a.missing()
+	}
+	value, err := strconv.Atoi(a.node.Value)
+	if a.node.Kind != NumberNode || err != nil {
+		return 0, a.Errorf("expected an integer")
+	}
+	return value, nil
+}
+
+// BigInt reads an integer of any size.
+func (a *Arg) BigInt() (*big.Int, error) {
+	if a.node == nil {
+		return nil, a.missing()
+	}
+	value, ok := new(big.Int).SetString(a.node.Value, 10)
+	if a.node.Kind != NumberNode || !ok {
+		return nil, a.Errorf("expected an integer")
+	}
+	return value, nil
+}
+
+// Float reads a number.
+func (a *Arg) Float() (float64, error) {
+	if a.node == nil {
+		return 0, a.missing()
+	}
+	value, err := strconv.ParseFloat(a.node.Value, 64)
+	if a.node.Kind != NumberNode || err != nil {
+		return 0, a.Errorf("expected a number")
+	}
+	return value, nil
+}
+
+// Str reads a string literal.
+func (a *Arg) Str() (string, error) {
+	if a.node == nil {
+		return "", a.missing()
+	}
+	if a.node.Kind != StringNode {
+		return "", a.Errorf("expected a string")
+	}
+	return a.node.Value, nil
+}
+
+// Ident reads an identifier, such as adjacency in format=adjacency.
+func (a *Arg) Ident() (string, error) {
+	if a.node == nil {
+		return "", a.missing()
+	}
+	if !a.isIdent() {
+		return "", a.Errorf("expected an identifier")
+	}
+	return a.node.Value, nil
+}
+
+// Value reads a literal value, either a number, a string or an identifier.
+func (a *Arg) Value() (string, error) {
+	if a.node == nil {
+		return "", a.missing()
+	}
+	if a.node.Kind != NumberNode && a.node.Kind != StringNode && !a.isIdent() {
+		return "", a.Errorf("expected a value")
+	}
+	return a.node.Value, nil
+}
+
+// Range reads a range of integers, such as 5..10, or a single integer.
+func (a *Arg) Range() (int, int, error) {
+	if a.node == nil {
+		return 0, 0, a.missing()
+	}
+	if a.node.Kind == NumberNode {
+		value, err := a.Int()
+		return value, value, err
+	}
+	if a.node.Kind != RangeNode {
+		return 0, 0, a.Errorf("expected an integer or a range of integers")
+	}
+
+	bounds := make([]int, 2)
+	for i, bound := range a.node.Args {
+		value, err := strconv.Atoi(bound.Value)
+		if bound.Kind != NumberNode || err != nil {
+			return 0, 0, errorf(bound.Col, "expected an integer")
+		}
+		bounds[i] = value
+	}
+	if bounds[0] > bounds[1] {
+		return 0, 0, a.Errorf("invalid bounds, %d is greater than %d", bounds[0], bounds[1])
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// Generator builds the generator for a descriptor.
+func (a *Arg) Generator() (RandomGenerator, error) {
+	if a.node == nil {
+		return nil, a.missing()
+	}
+	return buildGenerator(a.node)
+}
+
+// Weight reads the weight of the argument, such as 3 in enum(a:3,b:1), if any.
+func (a *Arg) Weight() (float64, bool, error) {
+	a.weightUsed = true
+	if a.node == nil || a.node.Weight == nil {
+		return 0, false, nil
+	}
+	value, err := strconv.ParseFloat(a.node.Weight.Value, 64)
+	if err != nil {
+		return 0, false, errorf(a.node.Weight.Col, "expected a number")
+	}
+	return value, true, nil
+}
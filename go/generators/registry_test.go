@@ -0,0 +1,113 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func init() {
+	// scan(int,float,bigint,string,desc) checks the arguments read by Scan.
+	Register("scan", func(p *Params) (RandomGenerator, error) {
+		var n int
+		var x float64
+		b := new(big.Int)
+		var s string
+		var g RandomGenerator
+		if err := p.Scan(&n, &x, b, &s, &g); err != nil {
+			return nil, err
+		}
+		if n != 3 || x != 0.5 || b.String() != "12345678901234567890" || s != "a,b" {
+			return nil, p.Errorf("unexpected arguments %d %g %s %q", n, x, b, s)
+		}
+		return g, nil
+	})
+	Register("scanbool", func(p *Params) (RandomGenerator, error) {
+		var b bool
+		return nil, p.Scan(&b)
+	})
+
+	// words(...) joins its raw arguments, such as words(a b, +, "c,d").
+	RegisterRaw("words", func(p *Params) (RandomGenerator, error) {
+		words := make([]string, p.Len())
+		for i := range words {
+			var err error
+			if words[i], err = p.Arg(i).Value(); err != nil {
+				return nil, err
+			}
+		}
+		return EnumRandomGenerator{Values: []string{strings.Join(words, "|")}}, nil
+	})
+}
+
+// mustPanic checks that a function panics with a message containing the
+// given one.
+func mustPanic(t *testing.T, msg string, f func()) {
+	t.Helper()
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected a panic with %q", msg)
+		} else if !strings.Contains(fmt.Sprint(r), msg) {
+			t.Errorf("got panic %q, expected %q", r, msg)
+		}
+	}()
+	f()
+}
+
+func TestRegister(t *testing.T) {
+	factory := func(p *Params) (RandomGenerator, error) { return BoolRandomGenerator{}, nil }
+	mustPanic(t, "factory is nil", func() { Register("nilfactory", nil) })
+	mustPanic(t, "called twice for int", func() { Register("int", factory) })
+	mustPanic(t, "called twice for enum", func() { RegisterRaw("enum", factory) })
+	mustPanic(t, "reserved name len", func() { Register("len", factory) })
+	mustPanic(t, "reserved name max", func() { RegisterRaw("max", factory) })
+
+	for desc, want := range map[string]string{
+		`words(a b, +, "c,d")`: "a b|+|c,d",
+		"words(x)":             "x",
+		"array(2,2)[words(1)]": "[1 1]",
+	} {
+		if got := generate(t, desc)[0].String(); got != want {
+			t.Errorf("%s: got %s, expected %s", desc, got, want)
+		}
+	}
+	checkErrors(t, map[string]string{
+		"words(a,)":          "expected a value",
+		"scan(a b)":          "unexpected",
+		"words(a)[int(1,2)]": "unexpected element descriptor for words",
+		"words(x:2)":         "unexpected weight for words",
+	})
+}
+
+func TestScan(t *testing.T) {
+	if _, ok := build(t, `scan(3,0.5,12345678901234567890,"a,b",bool)`).(BoolRandomGenerator); !ok {
+		t.Errorf("scan did not read the descriptor argument")
+	}
+
+	checkErrors(t, map[string]string{
+		"scanbool(true)":           "cannot read argument into *bool at column 10",
+		"scan(3,0.5,1,\"a\")":      "scan expects 5 arguments",
+		"scan(x,0.5,1,\"a\",bool)": "expected an integer",
+		"scan(3,0.5,1,a,bool)":     "expected a string",
+	})
+}
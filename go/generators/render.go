@@ -0,0 +1,358 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// Renderer renders a value as a literal of a language.
+type Renderer func(v Value) string
+
+// Renderers available for the formats of test inputs. The default format is
+// the one test inputs have always been formatted with, and the quoted format
+// is the one of the String method of values.
+var Renderers = map[string]Renderer{
+	"":       RenderDefault,
+	"quoted": Value.String,
+	"python": RenderPython,
+	"java":   RenderJava,
+	"c":      RenderC,
+	"json":   RenderJSON,
+}
+
+// FindRenderer finds the renderer for the given format.
+func FindRenderer(format string) (Renderer, error) {
+	renderer, ok := Renderers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s'", format)
+	}
+	return renderer, nil
+}
+
+func renderElems(elems []Value, render Renderer) string {
+	data := make([]string, len(elems))
+	for i, elem := range elems {
+		data[i] = render(elem)
+	}
+	return strings.Join(data, ", ")
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Default
+
+// RenderDefault renders a value as test inputs have always been formatted,
+// such as [1 2 3] for arrays, (1 true) for tuples and {name:Alice age:42} for
+// records, with the strings they contain written as such. Since these strings
+// cannot be told apart from separators when they contain spaces, colons or
+// brackets, tasks with such strings should use the quoted format instead.
+func RenderDefault(v Value) string {
+	var sb strings.Builder
+	v.write(&sb, false)
+	return sb.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Python
+
+// RenderPython renders a value as a Python literal, that can be read with
+// ast.literal_eval. Arrays are lists, records and maps are dictionaries, and
+// null values are None.
+func RenderPython(v Value) string {
+	switch v.Kind {
+	case NullKind:
+		return "None"
+	case BoolKind:
+		if v.Text == "true" {
+			return "True"
+		}
+		return "False"
+	case StringKind:
+		return strconv.Quote(v.Text)
+	case ArrayKind:
+		return "[" + renderElems(v.Elems, RenderPython) + "]"
+	case TupleKind:
+		if len(v.Elems) == 1 {
+			return "(" + RenderPython(v.Elems[0]) + ",)"
+		}
+		return "(" + renderElems(v.Elems, RenderPython) + ")"
+	case RecordKind, MapKind:
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			data[i] = RenderPython(key(v, i)) + ": " + RenderPython(elem)
+		}
+		return "{" + strings.Join(data, ", ") + "}"
+	}
+	return v.Text
+}
+
+// key returns the key of the i-th element of a record or of a map.
+func key(v Value, i int) Value {
+	if v.Kind == RecordKind {
+		return StringValue(v.Names[i])
+	}
+	return v.Keys[i]
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// JSON
+
+// RenderJSON renders a value as JSON. Arrays and tuples are arrays, records
+// are objects, and maps are objects when their keys are strings and arrays of
+// [key, value] pairs otherwise.
+func RenderJSON(v Value) string {
+	switch v.Kind {
+	case NullKind:
+		return "null"
+	case StringKind:
+		data, _ := json.Marshal(v.Text)
+		return string(data)
+	case ArrayKind, TupleKind:
+		return "[" + renderElems(v.Elems, RenderJSON) + "]"
+	case RecordKind, MapKind:
+		object := v.Kind == RecordKind
+		if v.Kind == MapKind {
+			object = true
+			for _, key := range v.Keys {
+				object = object && key.Kind == StringKind
+			}
+		}
+
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			if object {
+				data[i] = RenderJSON(key(v, i)) + ": " + RenderJSON(elem)
+			} else {
+				data[i] = "[" + RenderJSON(key(v, i)) + ", " + RenderJSON(elem) + "]"
+			}
+		}
+		if object {
+			return "{" + strings.Join(data, ", ") + "}"
+		}
+		return "[" + strings.Join(data, ", ") + "]"
+	}
+	return v.Text
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Java
+
+// RenderJava renders a value as a Java expression. Integers beyond 64 bits are
+// BigIntegers, arrays are typed arrays, such as new int[]{1, 2}, whose type
+// is Object[] when their elements are of several types or when they are empty
+// and whose elements are boxed when some are null, tuples are Object[] arrays,
+// and records and maps are immutable maps, or hash maps when some of their
+// elements are null.
+func RenderJava(v Value) string {
+	switch v.Kind {
+	case NullKind:
+		return "null"
+	case IntKind:
+		if t := javaType(v); t != "int" {
+			return renderJavaAs(v, t)
+		}
+	case StringKind:
+		return quoteJava(v.Text)
+	case ArrayKind:
+		t := javaType(v)
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			data[i] = renderJavaAs(elem, strings.TrimSuffix(t, "[]"))
+		}
+		return "new " + t + "{" + strings.Join(data, ", ") + "}"
+	case TupleKind:
+		return "new Object[]{" + renderElems(v.Elems, RenderJava) + "}"
+	case RecordKind, MapKind:
+		nullable := false
+		for i, elem := range v.Elems {
+			nullable = nullable || elem.Kind == NullKind || key(v, i).Kind == NullKind
+		}
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			if nullable {
+				data[i] = "put(" + RenderJava(key(v, i)) + ", " + RenderJava(elem) + ");"
+			} else {
+				data[i] = "java.util.Map.entry(" + RenderJava(key(v, i)) + ", " + RenderJava(elem) + ")"
+			}
+		}
+		if nullable {
+			// Immutable maps do not accept null keys nor values.
+			return "new java.util.HashMap<Object, Object>() {{ " + strings.Join(data, " ") + " }}"
+		}
+		return "java.util.Map.ofEntries(" + strings.Join(data, ", ") + ")"
+	}
+	return v.Text
+}
+
+// renderJavaAs renders a value as a Java expression of the given type, which
+// matters for integers that are not autoboxed to Long nor to BigInteger.
+func renderJavaAs(v Value, t string) string {
+	if v.Kind == IntKind {
+		switch t {
+		case "long", "Long":
+			return v.Text + "L"
+		case bigIntegerType:
+			return "new " + bigIntegerType + "(\"" + v.Text + "\")"
+		}
+	}
+	return RenderJava(v)
+}
+
+const bigIntegerType = "java.math.BigInteger"
+
+// javaType computes the type of a value in Java, which is null for null values.
+func javaType(v Value) string {
+	switch v.Kind {
+	case NullKind:
+		return "null"
+	case IntKind:
+		if _, err := strconv.ParseInt(v.Text, 10, 32); err == nil {
+			return "int"
+		}
+		if _, err := strconv.ParseInt(v.Text, 10, 64); err == nil {
+			return "long"
+		}
+		return bigIntegerType
+	case FloatKind:
+		return "double"
+	case BoolKind:
+		return "boolean"
+	case StringKind:
+		return "String"
+	case ArrayKind:
+		// Integers take the widest type of the elements.
+		widths := map[string]int{"int": 1, "long": 2, bigIntegerType: 3}
+		elem, nullable := "", false
+		for _, e := range v.Elems {
+			switch t := javaType(e); {
+			case t == "null":
+				nullable = true
+			case elem == "":
+				elem = t
+			case t != elem:
+				if widths[t] == 0 || widths[elem] == 0 {
+					return "Object[]"
+				}
+				if widths[t] > widths[elem] {
+					elem = t
+				}
+			}
+		}
+		if elem == "" {
+			return "Object[]"
+		}
+		if boxed, ok := javaBoxedTypes[elem]; ok && nullable {
+			elem = boxed
+		}
+		return elem + "[]"
+	}
+	return "Object"
+}
+
+var javaBoxedTypes = map[string]string{
+	"int":     "Integer",
+	"long":    "Long",
+	"double":  "Double",
+	"boolean": "Boolean",
+}
+
+func quoteJava(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, c := range s {
+		switch {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c == '\r':
+			sb.WriteString(`\r`)
+		case c < ' ' || c > '~':
+			for _, u := range utf16.Encode([]rune{c}) {
+				fmt.Fprintf(&sb, `\u%04x`, u)
+			}
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// C
+
+// RenderC renders a value as a C initialiser. Arrays and tuples are brace
+// lists, records use designated initialisers and maps are lists of {key, value}
+// pairs. Booleans require <stdbool.h>, strings are encoded in UTF-8 and null
+// values are NULL pointers.
+func RenderC(v Value) string {
+	switch v.Kind {
+	case NullKind:
+		return "NULL"
+	case StringKind:
+		return quoteC(v.Text)
+	case ArrayKind, TupleKind:
+		return "{" + renderElems(v.Elems, RenderC) + "}"
+	case RecordKind:
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			data[i] = "." + v.Names[i] + " = " + RenderC(elem)
+		}
+		return "{" + strings.Join(data, ", ") + "}"
+	case MapKind:
+		data := make([]string, len(v.Elems))
+		for i, elem := range v.Elems {
+			data[i] = "{" + RenderC(v.Keys[i]) + ", " + RenderC(elem) + "}"
+		}
+		return "{" + strings.Join(data, ", ") + "}"
+	}
+	return v.Text
+}
+
+func quoteC(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			sb.WriteByte('\\')
+			sb.WriteByte(c)
+		case c == '\n':
+			sb.WriteString(`\n`)
+		case c == '\t':
+			sb.WriteString(`\t`)
+		case c < ' ' || c > '~':
+			// Octal escapes have at most three digits, unlike hexadecimal ones.
+			fmt.Fprintf(&sb, `\%03o`, c)
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
@@ -0,0 +1,215 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"encoding/json"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestRenderDefault(t *testing.T) {
+	tests := []struct {
+		value Value
+		want  string
+	}{
+		{IntValue(-3), "-3"},
+		{StringValue("a b"), "a b"},
+		{StringValue("null"), "null"},
+		{ArrayValue([]Value{StringValue("a"), StringValue("b c")}), "[a b c]"},
+		{TupleValue([]Value{IntValue(1), BoolValue(true), StringValue("x:y")}), "(1 true x:y)"},
+		{RecordValue([]string{"name", "age"}, []Value{StringValue("Alice"), IntValue(42)}), "{name:Alice age:42}"},
+		{MapValue([]Value{StringValue("k")}, []Value{ArrayValue([]Value{NullValue(), StringValue(`"q"`)})}), `{k:[null "q"]}`},
+	}
+	for _, test := range tests {
+		if got := RenderDefault(test.value); got != test.want {
+			t.Errorf("got %s, expected %s", got, test.want)
+		}
+	}
+	if renderer, err := FindRenderer(""); err != nil || renderer(ArrayValue([]Value{StringValue("a")})) != "[a]" {
+		t.Error("the default format must leave strings unquoted")
+	}
+}
+
+func TestRenderQuoted(t *testing.T) {
+	tests := []struct {
+		value Value
+		want  string
+	}{
+		{IntValue(-3), "-3"},
+		{StringValue("a b"), "a b"},
+		{ArrayValue([]Value{IntValue(1), IntValue(2)}), "[1 2]"},
+		{ArrayValue([]Value{StringValue("aaa "), StringValue(" "), StringValue("")}), `["aaa " " " ""]`},
+		{TupleValue([]Value{IntValue(1), BoolValue(true), StringValue("x:y")}), `(1 true "x:y")`},
+		{RecordValue([]string{"name", "age"}, []Value{StringValue("Al [ice]"), IntValue(42)}), `{name:"Al [ice]" age:42}`},
+		{MapValue([]Value{StringValue("k v")}, []Value{StringValue(`"q"`)}), `{"k v":"\"q\""}`},
+		{ArrayValue([]Value{NullValue(), StringValue("<&>")}), `[null "<&>"]`},
+		{NullValue(), "null"},
+		{StringValue("null"), `"null"`},
+		{StringValue(`"a"`), `"\"a\""`},
+		{ArrayValue([]Value{StringValue("null")}), `["null"]`},
+	}
+	for _, test := range tests {
+		if got := test.value.String(); got != test.want {
+			t.Errorf("got %s, expected %s", got, test.want)
+		}
+	}
+	if renderer, err := FindRenderer("quoted"); err != nil || renderer(ArrayValue([]Value{StringValue("a")})) != `["a"]` {
+		t.Error("the quoted format must quote strings within collections")
+	}
+}
+
+func TestRenderLanguages(t *testing.T) {
+	big := IntValue(0)
+	big.Text = "123456789012345678901234567890"
+	tests := []struct {
+		value               Value
+		python, java, c, js string
+	}{
+		{BoolValue(true), "True", "true", "true", "true"},
+		{NullValue(), "None", "null", "NULL", "null"},
+		{FloatValue(2.5, 2), "2.50", "2.50", "2.50", "2.50"},
+		{StringValue("é\"\n"), `"é\"\n"`, `"\u00e9\"\n"`, `"\303\251\"\n"`, `"é\"\n"`},
+		{ArrayValue([]Value{IntValue(1), IntValue(5000000000)}), "[1, 5000000000]", "new long[]{1L, 5000000000L}", "{1, 5000000000}", "[1, 5000000000]"},
+		{ArrayValue([]Value{IntValue(1), big}), "[1, " + big.Text + "]", `new java.math.BigInteger[]{new java.math.BigInteger("1"), new java.math.BigInteger("` + big.Text + `")}`, "{1, " + big.Text + "}", "[1, " + big.Text + "]"},
+		{ArrayValue([]Value{IntValue(1), NullValue()}), "[1, None]", "new Integer[]{1, null}", "{1, NULL}", "[1, null]"},
+		{ArrayValue(nil), "[]", "new Object[]{}", "{}", "[]"},
+		{TupleValue([]Value{StringValue("a")}), `("a",)`, `new Object[]{"a"}`, `{"a"}`, `["a"]`},
+		{RecordValue([]string{"x", "y"}, []Value{IntValue(1), BoolValue(false)}), `{"x": 1, "y": False}`, `java.util.Map.ofEntries(java.util.Map.entry("x", 1), java.util.Map.entry("y", false))`, "{.x = 1, .y = false}", `{"x": 1, "y": false}`},
+		{MapValue([]Value{IntValue(2)}, []Value{NullValue()}), "{2: None}", "new java.util.HashMap<Object, Object>() {{ put(2, null); }}", "{{2, NULL}}", "[[2, null]]"},
+	}
+	for _, test := range tests {
+		for _, render := range []struct {
+			format string
+			want   string
+		}{{"python", test.python}, {"java", test.java}, {"c", test.c}, {"json", test.js}} {
+			renderer, err := FindRenderer(render.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := renderer(test.value); got != render.want {
+				t.Errorf("%s: got %s, expected %s", render.format, got, render.want)
+			}
+		}
+	}
+	if _, err := FindRenderer("cobol"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
+
+func TestRenderLiterals(t *testing.T) {
+	tests := []struct {
+		literal             string
+		python, java, c, js string
+	}{
+		{"42", "42", "42", "42", "42"},
+		{"-0", "-0", "-0", "-0", "-0"},
+		{"1.50", "1.50", "1.50", "1.50", "1.50"},
+		{"-2e-3", "-2e-3", "-2e-3", "-2e-3", "-2e-3"},
+		{"007", `"007"`, `"007"`, `"007"`, `"007"`},
+		{"+5", `"+5"`, `"+5"`, `"+5"`, `"+5"`},
+		{".5", `".5"`, `".5"`, `".5"`, `".5"`},
+		{"5.", `"5."`, `"5."`, `"5."`, `"5."`},
+		{"inf", `"inf"`, `"inf"`, `"inf"`, `"inf"`},
+		{"NaN", `"NaN"`, `"NaN"`, `"NaN"`, `"NaN"`},
+		{"0x1F", `"0x1F"`, `"0x1F"`, `"0x1F"`, `"0x1F"`},
+	}
+	for _, test := range tests {
+		value := literalValue(test.literal)
+		for _, render := range []struct {
+			format string
+			want   string
+		}{{"python", test.python}, {"java", test.java}, {"c", test.c}, {"json", test.js}} {
+			renderer, err := FindRenderer(render.format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := renderer(value); got != render.want {
+				t.Errorf("%s: %s rendered as %s, expected %s", render.format, test.literal, got, render.want)
+			}
+		}
+		if got := value.String(); got != test.literal {
+			t.Errorf("%s is formatted as %s", test.literal, got)
+		}
+	}
+}
+
+// decoded converts a value to what decoding its JSON rendering gives.
+func decoded(v Value) interface{} {
+	switch v.Kind {
+	case NullKind:
+		return nil
+	case IntKind, FloatKind:
+		x, _ := strconv.ParseFloat(v.Text, 64)
+		return x
+	case BoolKind:
+		return v.Text == "true"
+	case StringKind:
+		return v.Text
+	case ArrayKind, TupleKind:
+		elems := make([]interface{}, len(v.Elems))
+		for i, elem := range v.Elems {
+			elems[i] = decoded(elem)
+		}
+		return elems
+	}
+	object := v.Kind == RecordKind
+	if v.Kind == MapKind {
+		object = true
+		for _, k := range v.Keys {
+			object = object && k.Kind == StringKind
+		}
+	}
+	fields := make(map[string]interface{})
+	pairs := make([]interface{}, len(v.Elems))
+	for i, elem := range v.Elems {
+		fields[key(v, i).Text] = decoded(elem)
+		pairs[i] = []interface{}{decoded(key(v, i)), decoded(elem)}
+	}
+	if object {
+		return fields
+	}
+	return pairs
+}
+
+func TestRenderJSONRoundTrip(t *testing.T) {
+	descs := []string{
+		"array(0,4)[optional(0.3)[str(0,4,unicode)]]",
+		"record{a:tuple(int(-9,9),bool),b:map(0,3)[str(1,3)->float(0,1)|precision(2)]}",
+		"map(0,3)[int(0,9)->array(0,2)[enum(x,null,\"null\")]]",
+		"array(0,5)[enum(007,+5,.5,inf,nan,1.50,-0,1e3)]",
+	}
+	for _, desc := range descs {
+		g := build(t, desc)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < samples; i++ {
+			value := GenerateValue(g, r)
+			var got interface{}
+			if err := json.Unmarshal([]byte(RenderJSON(value)), &got); err != nil {
+				t.Fatalf("%s: %s does not decode: %s", desc, RenderJSON(value), err)
+			}
+			if want := decoded(value); !reflect.DeepEqual(got, want) {
+				t.Errorf("%s: %s decodes to %v, expected %v", desc, RenderJSON(value), got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,421 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Shrinker is implemented by generators able to propose smaller candidates
+// for a value they generated, to simplify a failing test input. Candidates
+// are valid values for the generator, the most aggressive ones first.
+type Shrinker interface {
+	Shrink(value Value) []Value
+}
+
+// Shrink proposes smaller candidates for a value generated by a generator,
+// or none if the generator is not a Shrinker.
+func Shrink(g RandomGenerator, value Value) []Value {
+	if shrinker, ok := g.(Shrinker); ok {
+		return shrinker.Shrink(value)
+	}
+	return nil
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Numbers
+
+// Shrink proposes integers closer to zero, within the bounds.
+func (g IntRandomGenerator) Shrink(value Value) []Value {
+	return shrinkInt(value, g.Min, g.Max)
+}
+
+// Shrink proposes integers closer to zero, within the bounds.
+func (g BigIntRandomGenerator) Shrink(value Value) []Value {
+	v, ok := new(big.Int).SetString(value.Text, 10)
+	if value.Kind != IntKind || !ok || v.Cmp(g.Min) < 0 || v.Cmp(g.Max) > 0 {
+		return nil
+	}
+	target := new(big.Int)
+	if target.Cmp(g.Min) < 0 {
+		target.Set(g.Min)
+	} else if target.Cmp(g.Max) > 0 {
+		target.Set(g.Max)
+	}
+
+	var candidates []Value
+	two := big.NewInt(2)
+	for d := new(big.Int).Sub(v, target); d.Sign() != 0; d.Quo(d, two) {
+		candidates = append(candidates, BigIntValue(new(big.Int).Sub(v, d)))
+	}
+	return candidates
+}
+
+// Shrink proposes the missing value, and then smaller values.
+func (g OptionalRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind == NullKind {
+		return nil
+	}
+	var candidates []Value
+	if g.P > 0 {
+		candidates = append(candidates, NullValue())
+	}
+	return append(candidates, Shrink(g.Generator, value)...)
+}
+
+// Shrink proposes numbers closer to zero, within the bounds.
+func (g FloatRandomGenerator) Shrink(value Value) []Value {
+	return shrinkFloat(value, g.Min, g.Max, g.Precision)
+}
+
+// Shrink proposes numbers closer to zero, within the clamp bounds if any.
+func (g NormalRandomGenerator) Shrink(value Value) []Value {
+	min, max := math.Inf(-1), math.Inf(1)
+	if g.Clamp != nil {
+		min, max = g.Clamp.Min, g.Clamp.Max
+	}
+	return shrinkFloat(value, min, max, g.Precision)
+}
+
+// Shrink proposes numbers closer to zero, within the clamp bounds if any.
+func (g ExponentialRandomGenerator) Shrink(value Value) []Value {
+	min, max := 0.0, math.Inf(1)
+	if g.Clamp != nil {
+		min, max = math.Max(min, g.Clamp.Min), g.Clamp.Max
+	}
+	return shrinkFloat(value, min, max, g.Precision)
+}
+
+// Shrink proposes integers closer to zero, within the clamp bounds if any.
+func (g PoissonRandomGenerator) Shrink(value Value) []Value {
+	return shrinkInt(value, g.Clamp.applyInt(0), g.Clamp.applyInt(math.MaxInt32))
+}
+
+// Shrink proposes integers closer to one, within the clamp bounds if any.
+func (g GeometricRandomGenerator) Shrink(value Value) []Value {
+	return shrinkInt(value, g.Clamp.applyInt(1), g.Clamp.applyInt(math.MaxInt32))
+}
+
+// shrinkInt proposes integers between the value and the one closest to zero
+// in the bounds, by halving the distance between them.
+func shrinkInt(value Value, min int, max int) []Value {
+	v, err := strconv.Atoi(value.Text)
+	if value.Kind != IntKind || err != nil || v < min || v > max {
+		return nil
+	}
+	target := 0
+	if target < min {
+		target = min
+	} else if target > max {
+		target = max
+	}
+
+	var candidates []Value
+	for d := v - target; d != 0; d /= 2 {
+		candidates = append(candidates, IntValue(v-d))
+	}
+	return candidates
+}
+
+// shrinkFloat proposes numbers between the value and the one closest to zero
+// in the bounds, the integral part of the value and halfway numbers.
+func shrinkFloat(value Value, min float64, max float64, precision int) []Value {
+	v, err := strconv.ParseFloat(value.Text, 64)
+	if value.Kind != FloatKind || err != nil || v < min || v > max {
+		return nil
+	}
+	target := math.Max(min, math.Min(max, 0))
+
+	seen := map[string]bool{value.Text: true}
+	var candidates []Value
+	add := func(x float64) {
+		if candidate := FloatValue(x, precision); !seen[candidate.Text] && min <= x && x <= max {
+			seen[candidate.Text] = true
+			candidates = append(candidates, candidate)
+		}
+	}
+	add(target)
+	add(math.Trunc(v))
+	for d, i := (v-target)/2, 0; i < 8; d, i = d/2, i+1 {
+		add(v - d)
+	}
+	return candidates
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Values
+
+// Shrink proposes false for true.
+func (g BoolRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind == BoolKind && value.Text == "true" {
+		return []Value{BoolValue(false)}
+	}
+	return nil
+}
+
+// Shrink proposes the values coming before the given one in the enumeration.
+func (g EnumRandomGenerator) Shrink(value Value) []Value {
+	var candidates []Value
+	for i, v := range g.Values {
+		if v == value.Text {
+			return candidates
+		}
+		if g.Weights == nil || g.Weights[i] > 0 {
+			candidates = append(candidates, literalValue(v))
+		}
+	}
+	return nil
+}
+
+// Shrink proposes shorter strings, and strings whose characters are replaced
+// by the first one of the alphabet.
+func (g StringRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != StringKind {
+		return nil
+	}
+	alphabet := g.Alphabet
+	if alphabet == nil {
+		alphabet = characterClasses["alnum"]
+	}
+	first := StringValue(string(alphabet[0]))
+
+	var chars []Value
+	for _, c := range value.Text {
+		chars = append(chars, StringValue(string(c)))
+	}
+	var candidates []Value
+	for _, candidate := range shrinkList(chars, g.MinLength, func(c Value) []Value {
+		if c.Text != first.Text {
+			return []Value{first}
+		}
+		return nil
+	}) {
+		var sb strings.Builder
+		for _, c := range candidate {
+			sb.WriteString(c.Text)
+		}
+		candidates = append(candidates, StringValue(sb.String()))
+	}
+	return candidates
+}
+
+// Shrink proposes the lines coming before the given one in the text file.
+func (g PickRandomGenerator) Shrink(value Value) []Value {
+	var candidates []Value
+	for _, line := range g.Corpus.distinctLines() {
+		if line == value.Text {
+			return candidates
+		}
+		candidates = append(candidates, StringValue(line))
+	}
+	return nil
+}
+
+// Shrink proposes sentences with fewer words, and sentences whose words are
+// replaced by the first one of the text file.
+func (g SentenceRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != StringKind || len(g.Corpus.Words) == 0 {
+		return nil
+	}
+	first := StringValue(g.Corpus.Words[0])
+
+	var words []Value
+	for _, word := range strings.Fields(value.Text) {
+		words = append(words, StringValue(word))
+	}
+	var candidates []Value
+	for _, candidate := range shrinkList(words, g.MinWords, func(word Value) []Value {
+		if word.Text != first.Text {
+			return []Value{first}
+		}
+		return nil
+	}) {
+		texts := make([]string, len(candidate))
+		for i, word := range candidate {
+			texts[i] = word.Text
+		}
+		candidates = append(candidates, StringValue(strings.Join(texts, " ")))
+	}
+	return candidates
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Collections
+
+// Shrink proposes arrays with fewer elements, and arrays whose elements are
+// shrunk, keeping them sorted and distinct when required.
+func (g ArrayRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != ArrayKind {
+		return nil
+	}
+
+	var candidates []Value
+	for _, candidate := range shrinkList(value.Elems, g.MinLength, func(v Value) []Value {
+		return Shrink(g.Generator, v)
+	}) {
+		if g.Unique && hasDuplicates(candidate) {
+			continue
+		}
+		if g.Sorted || g.Descending {
+			g.sort(candidate)
+		}
+		candidates = append(candidates, ArrayValue(candidate))
+	}
+	return candidates
+}
+
+// Shrink proposes lists with fewer elements, lists whose nested lists are
+// replaced by one of their values, and lists whose elements are shrunk.
+func (g NestedRandomGenerator) Shrink(value Value) []Value {
+	return g.shrink(value, 1)
+}
+
+func (g NestedRandomGenerator) shrink(value Value, depth int) []Value {
+	if value.Kind != ArrayKind {
+		return nil
+	}
+
+	var candidates []Value
+	for _, candidate := range shrinkList(value.Elems, 0, func(v Value) []Value {
+		// Values at the maximum depth are not nested lists, even if they are
+		// arrays themselves.
+		if depth == g.MaxDepth || v.Kind != ArrayKind {
+			return Shrink(g.Generator, v)
+		}
+		var elems []Value
+		for _, elem := range v.Elems {
+			if depth+1 == g.MaxDepth || elem.Kind != ArrayKind {
+				elems = append(elems, elem)
+				break
+			}
+		}
+		return append(elems, g.shrink(v, depth+1)...)
+	}) {
+		candidates = append(candidates, ArrayValue(candidate))
+	}
+	return candidates
+}
+
+// Shrink proposes maps with fewer entries, and maps whose values are shrunk.
+func (g MapRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != MapKind {
+		return nil
+	}
+
+	entries := make([]Value, len(value.Elems))
+	for i := range entries {
+		entries[i] = TupleValue([]Value{value.Keys[i], value.Elems[i]})
+	}
+	var candidates []Value
+	for _, candidate := range shrinkList(entries, g.MinLength, func(entry Value) []Value {
+		var entries []Value
+		for _, v := range Shrink(g.ValueGenerator, entry.Elems[1]) {
+			entries = append(entries, TupleValue([]Value{entry.Elems[0], v}))
+		}
+		return entries
+	}) {
+		keys := make([]Value, len(candidate))
+		data := make([]Value, len(candidate))
+		for i, entry := range candidate {
+			keys[i], data[i] = entry.Elems[0], entry.Elems[1]
+		}
+		candidates = append(candidates, MapValue(keys, data))
+	}
+	return candidates
+}
+
+// Shrink proposes tuples with one shrunk component.
+func (g TupleRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != TupleKind || len(value.Elems) != len(g.Generators) {
+		return nil
+	}
+
+	var candidates []Value
+	for _, data := range shrinkComponents(g.Generators, value.Elems) {
+		candidates = append(candidates, TupleValue(data))
+	}
+	return candidates
+}
+
+// Shrink proposes records with one shrunk field.
+func (g RecordRandomGenerator) Shrink(value Value) []Value {
+	if value.Kind != RecordKind || len(value.Elems) != len(g.Generators) {
+		return nil
+	}
+
+	var candidates []Value
+	for _, data := range shrinkComponents(g.Generators, value.Elems) {
+		candidates = append(candidates, RecordValue(g.Names, data))
+	}
+	return candidates
+}
+
+// shrinkComponents proposes components where one of them is shrunk with the
+// corresponding generator.
+func shrinkComponents(gens []RandomGenerator, data []Value) [][]Value {
+	var candidates [][]Value
+	for i, generator := range gens {
+		for _, v := range Shrink(generator, data[i]) {
+			candidate := append([]Value{}, data...)
+			candidate[i] = v
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// shrinkList proposes lists with at least min elements, first by removing
+// chunks of decreasing sizes, then by shrinking one element at a time.
+func shrinkList(data []Value, min int, shrinkElem func(Value) []Value) [][]Value {
+	var candidates [][]Value
+	n := len(data)
+	if n > min {
+		candidates = append(candidates, append([]Value{}, data[:min]...))
+		for k := (n - min + 1) / 2; k > 0; k /= 2 {
+			for start := 0; start+k <= n; start += k {
+				candidate := append(append([]Value{}, data[:start]...), data[start+k:]...)
+				candidates = append(candidates, candidate)
+			}
+		}
+	}
+
+	for i := range data {
+		for _, v := range shrinkElem(data[i]) {
+			candidate := append([]Value{}, data...)
+			candidate[i] = v
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+func hasDuplicates(data []Value) bool {
+	seen := make(map[string]bool, len(data))
+	for _, v := range data {
+		if seen[v.String()] {
+			return true
+		}
+		seen[v.String()] = true
+	}
+	return false
+}
@@ -0,0 +1,118 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"strings"
+	"testing"
+)
+
+// shrinkValue shrinks a value as long as a candidate still fails, taking the
+// first failing candidate each time.
+func shrinkValue(g RandomGenerator, value Value, fails func(Value) bool) Value {
+	for steps := 0; steps < 1000; steps++ {
+		shrunk := false
+		for _, candidate := range Shrink(g, value) {
+			if fails(candidate) {
+				value, shrunk = candidate, true
+				break
+			}
+		}
+		if !shrunk {
+			break
+		}
+	}
+	return value
+}
+
+func TestShrink(t *testing.T) {
+	number := func(v Value) float64 {
+		x, _ := v.Number()
+		return x
+	}
+	always := func(Value) bool { return true }
+	tests := []struct {
+		desc  string
+		fails func(Value) bool
+		want  string // or empty for the tuple and map checked below
+	}{
+		{"int(-100,100)", func(v Value) bool { return number(v) >= 17 }, "17"},
+		{"int(-100,100)", func(v Value) bool { return number(v) <= -17 }, "-17"},
+		{"int(5,100)", always, "5"},
+		{"bigint(-1000000000000000000000000000000,-100000000000000000000)", always, "-100000000000000000000"},
+		{"float(-10,10)|precision(1)", func(v Value) bool { return number(v) > 1.5 }, "1.6"},
+		{"poisson(10)|clamp(3,20)", always, "3"},
+		{"geometric(0.1)", always, "1"},
+		{"bool", always, "false"},
+		{"optional(0.5)[int(1,9)]", always, "null"},
+		{"array(0,10)[int(0,100)]", func(v Value) bool {
+			for _, elem := range v.Elems {
+				if number(elem) >= 50 {
+					return true
+				}
+			}
+			return false
+		}, "[50]"},
+		{"array(2,5)[int(0,9)]|sorted|unique", always, "[0 1]"},
+		{"str(0,10,lower)", func(v Value) bool { return strings.Contains(v.Text, "z") }, "z"},
+		{"tuple(int(0,9),int(0,9))", func(v Value) bool { return number(v.Elems[0])+number(v.Elems[1]) >= 5 }, ""},
+		{"record{a:int(0,9),b:bool}", always, "{a:0 b:false}"},
+		{"map(1,3)[int(0,9)->int(0,9)]", always, ""},
+	}
+	for _, test := range tests {
+		g := build(t, test.desc)
+		for _, value := range generate(t, test.desc)[:20] {
+			if !test.fails(value) {
+				continue
+			}
+			shrunk := shrinkValue(g, value, test.fails)
+			if test.want != "" && shrunk.String() != test.want {
+				t.Errorf("%s: %s shrunk to %s, expected %s", test.desc, value, shrunk, test.want)
+			}
+			if test.want == "" && shrunk.Kind == TupleKind && number(shrunk.Elems[0])+number(shrunk.Elems[1]) != 5 {
+				t.Errorf("%s: %s shrunk to %s, expected a sum of 5", test.desc, value, shrunk)
+			}
+			// Keys are kept as is, since shrinking them could make them collide.
+			if test.want == "" && shrunk.Kind == MapKind && (shrunk.Len() != 1 || shrunk.Elems[0].Text != "0" || shrunk.Keys[0].Text != value.Keys[0].Text) {
+				t.Errorf("%s: %s shrunk to %s, expected its first key with 0", test.desc, value, shrunk)
+			}
+		}
+	}
+}
+
+func TestShrinkBounds(t *testing.T) {
+	for _, desc := range []string{"int(5,100)", "float(-3,-1)", "array(2,4)[int(-5,5)]|unique", "str(2,4)", "enum(a,b,c)", "map(2,3)[int(0,5)->bool]"} {
+		g := build(t, desc)
+		for _, value := range generate(t, desc)[:20] {
+			for _, candidate := range Shrink(g, value) {
+				if candidate.String() == value.String() {
+					t.Errorf("%s: %s proposed as a candidate for itself", desc, candidate)
+				}
+				switch x, ok := candidate.Number(); {
+				case ok && strings.HasPrefix(desc, "int") && (x < 5 || x > 100),
+					ok && strings.HasPrefix(desc, "float") && (x < -3 || x > -1):
+					t.Errorf("%s: candidate %s out of bounds", desc, candidate)
+				case desc != "enum(a,b,c)" && !ok && (candidate.Len() < 2 || candidate.Len() > 4):
+					t.Errorf("%s: candidate %s has an invalid length", desc, candidate)
+				}
+			}
+		}
+	}
+}
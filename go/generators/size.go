@@ -0,0 +1,192 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/big"
+)
+
+// Resizer is implemented by generators whose bounds can be scaled down, so
+// that they produce smaller values. The size goes from 0, for the simplest
+// values, such as the number closest to zero or the shortest arrays, to 1, for
+// the bounds of the descriptor.
+type Resizer interface {
+	Resize(size float64) RandomGenerator
+}
+
+// Resize scales down the bounds of a generator, provided it is a Resizer.
+func Resize(g RandomGenerator, size float64) RandomGenerator {
+	if resizer, ok := g.(Resizer); ok && size < 1 {
+		return resizer.Resize(math.Max(size, 0))
+	}
+	return g
+}
+
+// scaleInt scales the distance between two integers.
+func scaleInt(from int, to int, size float64) int {
+	d := (float64(to) - float64(from)) * size
+	if math.Abs(d) >= math.Abs(float64(to)-float64(from)) {
+		return to
+	}
+	return from + int(d)
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Values
+
+// Resize scales the bounds around the integer closest to zero.
+func (g IntRandomGenerator) Resize(size float64) RandomGenerator {
+	target := 0
+	if target < g.Min {
+		target = g.Min
+	} else if target > g.Max {
+		target = g.Max
+	}
+	g.Min, g.Max = scaleInt(target, g.Min, size), scaleInt(target, g.Max, size)
+	return g
+}
+
+// Resize scales the bounds around the integer closest to zero.
+func (g BigIntRandomGenerator) Resize(size float64) RandomGenerator {
+	target := new(big.Int)
+	if target.Cmp(g.Min) < 0 {
+		target.Set(g.Min)
+	} else if target.Cmp(g.Max) > 0 {
+		target.Set(g.Max)
+	}
+	scale := func(to *big.Int) *big.Int {
+		d := new(big.Float).SetInt(new(big.Int).Sub(to, target))
+		d.Mul(d, big.NewFloat(size))
+		i, _ := d.Int(nil)
+		return i.Add(i, target)
+	}
+	g.Min, g.Max = scale(g.Min), scale(g.Max)
+	return g
+}
+
+// Resize scales the bounds around the number closest to zero.
+func (g FloatRandomGenerator) Resize(size float64) RandomGenerator {
+	target := math.Max(g.Min, math.Min(g.Max, 0))
+	g.Min, g.Max = target+(g.Min-target)*size, target+(g.Max-target)*size
+	return g
+}
+
+// Resize scales the maximum length of strings.
+func (g StringRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxLength = scaleInt(g.MinLength, g.MaxLength, size)
+	return g
+}
+
+// Resize scales the maximum depth of the derivation trees.
+func (g GrammarRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxDepth = scaleInt(g.Grammar.depths[g.Grammar.Start], g.MaxDepth, size)
+	return g
+}
+
+// Resize scales the maximum number of words of sentences.
+func (g SentenceRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxWords = scaleInt(g.MinWords, g.MaxWords, size)
+	return g
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Collections
+
+// Resize scales the maximum length of arrays and their elements. Elements of
+// arrays with distinct elements are not scaled when there would not be enough
+// distinct ones.
+func (g ArrayRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxLength = scaleInt(g.MinLength, g.MaxLength, size)
+	generator := Resize(g.Generator, size)
+	if n, ok := cardinality(generator); !g.Unique || (ok && n >= g.MaxLength) {
+		g.Generator = generator
+	}
+	return g
+}
+
+// Resize scales the maximum number of entries of maps and their values.
+func (g MapRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxLength = scaleInt(g.MinLength, g.MaxLength, size)
+	g.ValueGenerator = Resize(g.ValueGenerator, size)
+	return g
+}
+
+// Resize scales the components of tuples.
+func (g TupleRandomGenerator) Resize(size float64) RandomGenerator {
+	g.Generators = resizeAll(g.Generators, size)
+	return g
+}
+
+// Resize scales the fields of records.
+func (g RecordRandomGenerator) Resize(size float64) RandomGenerator {
+	g.Generators = resizeAll(g.Generators, size)
+	return g
+}
+
+// Resize scales the maximum depth, width and size of nested lists, and the
+// values they contain.
+func (g NestedRandomGenerator) Resize(size float64) RandomGenerator {
+	g.MaxDepth = scaleInt(1, g.MaxDepth, size)
+	g.MaxWidth = scaleInt(0, g.MaxWidth, size)
+	g.MaxSize = scaleInt(0, g.MaxSize, size)
+	g.Generator = Resize(g.Generator, size)
+	return g
+}
+
+// Resize scales the numbers of vertices and edges of graphs, and their weights.
+// The number of vertices is not scaled when there would be too few of them
+// for the minimum number of edges.
+func (g GraphRandomGenerator) Resize(size float64) RandomGenerator {
+	n := scaleInt(g.MinNodes, g.MaxNodes, size)
+	if _, max := g.edgeBounds(n); max >= g.MinEdges {
+		g.MaxNodes = n
+	}
+	if g.MaxEdges >= 0 {
+		g.MaxEdges = scaleInt(g.MinEdges, g.MaxEdges, size)
+	}
+	if g.Weights != nil {
+		g.Weights = Resize(g.Weights, size)
+	}
+	return g
+}
+
+func resizeAll(gens []RandomGenerator, size float64) []RandomGenerator {
+	resized := make([]RandomGenerator, len(gens))
+	for i, generator := range gens {
+		resized[i] = Resize(generator, size)
+	}
+	return resized
+}
+
+////////////////////////////////////////////////////////////////////////////////
+// Optional values
+
+// Resize scales the values of the generator.
+func (g OptionalRandomGenerator) Resize(size float64) RandomGenerator {
+	g.Generator = Resize(g.Generator, size)
+	return g
+}
+
+// Resize scales the values of all the generators.
+func (g OneOfRandomGenerator) Resize(size float64) RandomGenerator {
+	g.Generators = resizeAll(g.Generators, size)
+	return g
+}
@@ -0,0 +1,107 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"testing"
+)
+
+func TestScaleInt(t *testing.T) {
+	tests := []struct {
+		from, to int
+		size     float64
+		expected int
+	}{
+		{0, 10, 0, 0},
+		{0, 10, 0.5, 5},
+		{0, 10, 1, 10},
+		{0, 7, 0.99, 6},
+		{5, -5, 0.5, 0},
+		{-3, -3, 0.7, -3},
+		{0, math.MaxInt32, 1, math.MaxInt32},
+		{math.MinInt32, math.MaxInt32, 1, math.MaxInt32},
+	}
+	for _, test := range tests {
+		if got := scaleInt(test.from, test.to, test.size); got != test.expected {
+			t.Errorf("scaleInt(%d, %d, %g) = %d, expected %d", test.from, test.to, test.size, got, test.expected)
+		}
+	}
+}
+
+// number parses an integer or a floating-point number value.
+func number(t *testing.T, v Value) float64 {
+	t.Helper()
+	x, err := strconv.ParseFloat(v.Text, 64)
+	if err != nil {
+		t.Fatalf("%s is not a number", v)
+	}
+	return x
+}
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		desc  string
+		size  float64
+		check func(t *testing.T, v Value) bool
+	}{
+		{"int(-100,50)", 0.1, func(t *testing.T, v Value) bool {
+			return number(t, v) >= -10 && number(t, v) <= 5
+		}},
+		{"int(10,100)", 0, func(t *testing.T, v Value) bool { return number(t, v) == 10 }},
+		{"int(-100,-20)", -1, func(t *testing.T, v Value) bool { return number(t, v) == -20 }},
+		{"bigint(-1000000000000000000000,1000000000000000000000)", 0, func(t *testing.T, v Value) bool {
+			return v.Text == "0"
+		}},
+		{"float(-2,4)", 0.5, func(t *testing.T, v Value) bool {
+			return number(t, v) >= -1 && number(t, v) <= 2
+		}},
+		{"str(1,20)", 0, func(t *testing.T, v Value) bool { return len(v.Text) == 1 }},
+		{"array(2,10)[int(0,100)]", 0, func(t *testing.T, v Value) bool {
+			return len(v.Elems) == 2 && v.Elems[0].Text == "0" && v.Elems[1].Text == "0"
+		}},
+		{"array(4,8)[int(1,4)]|unique", 0, func(t *testing.T, v Value) bool {
+			seen := make(map[string]bool)
+			for _, elem := range v.Elems {
+				seen[elem.Text] = true
+			}
+			return len(v.Elems) == 4 && len(seen) == 4
+		}},
+		{"map(1,10)[int(0,100)->int(-5,5)]", 0, func(t *testing.T, v Value) bool {
+			return len(v.Keys) == 1 && v.Elems[0].Text == "0"
+		}},
+		{"tuple(int(0,10),str(0,10))", 0, func(t *testing.T, v Value) bool {
+			return v.Elems[0].Text == "0" && v.Elems[1].Text == ""
+		}},
+		{"int(0,5)", 2, func(t *testing.T, v Value) bool { return number(t, v) >= 0 && number(t, v) <= 5 }},
+	}
+	for _, test := range tests {
+		g := Resize(build(t, test.desc), test.size)
+		r := rand.New(rand.NewSource(1))
+		for i := 0; i < samples; i++ {
+			if v := GenerateValue(g, r); !test.check(t, v) {
+				t.Errorf("%s: %s is out of the bounds of size %g", test.desc, v, test.size)
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,251 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package generators
+
+import (
+	"encoding/json"
+	"math/big"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Kind is the type of a generated value.
+type Kind int
+
+const (
+	RawKind Kind = iota
+	IntKind
+	FloatKind
+	BoolKind
+	StringKind
+	ArrayKind
+	TupleKind
+	RecordKind
+	MapKind
+	NullKind
+)
+
+// Value is a typed generated value. Scalars are given by their text, that is
+// the digits of numbers, true or false for booleans and the characters of
+// strings. Arrays, tuples and records have elements, records with the names
+// of their fields, and maps have keys with the corresponding elements. Raw
+// values are preformatted text from generators not producing typed values,
+// and null values stand for missing values.
+type Value struct {
+	Kind  Kind     `json:"kind"`
+	Text  string   `json:"text,omitempty"`
+	Elems []Value  `json:"elems,omitempty"`
+	Keys  []Value  `json:"keys,omitempty"`
+	Names []string `json:"names,omitempty"`
+}
+
+// ValueGenerator is implemented by generators producing typed values, which
+// are formatted by their Generate method.
+type ValueGenerator interface {
+	GenerateValue(r *rand.Rand) Value
+}
+
+// GenerateValue generates a typed value, which is a raw one for generators
+// that are not ValueGenerators.
+func GenerateValue(g RandomGenerator, r *rand.Rand) Value {
+	if generator, ok := g.(ValueGenerator); ok {
+		return generator.GenerateValue(r)
+	}
+	return RawValue(g.Generate(r))
+}
+
+// IntValue makes an integer value.
+func IntValue(i int) Value {
+	return Value{Kind: IntKind, Text: strconv.Itoa(i)}
+}
+
+// BigIntValue makes an integer value of any size.
+func BigIntValue(x *big.Int) Value {
+	return Value{Kind: IntKind, Text: x.String()}
+}
+
+// FloatValue makes a number value, rounded to the given number of decimals, or
+// to six decimals if it is negative.
+func FloatValue(x float64, precision int) Value {
+	return Value{Kind: FloatKind, Text: formatFloat(x, precision)}
+}
+
+// BoolValue makes a boolean value.
+func BoolValue(b bool) Value {
+	return Value{Kind: BoolKind, Text: strconv.FormatBool(b)}
+}
+
+// StringValue makes a string value.
+func StringValue(s string) Value {
+	return Value{Kind: StringKind, Text: s}
+}
+
+// RawValue makes a value from preformatted text.
+func RawValue(s string) Value {
+	return Value{Kind: RawKind, Text: s}
+}
+
+// ArrayValue makes an array of values.
+func ArrayValue(elems []Value) Value {
+	return Value{Kind: ArrayKind, Elems: elems}
+}
+
+// TupleValue makes a tuple of values.
+func TupleValue(elems []Value) Value {
+	return Value{Kind: TupleKind, Elems: elems}
+}
+
+// RecordValue makes a record whose fields have the given names.
+func RecordValue(names []string, elems []Value) Value {
+	return Value{Kind: RecordKind, Names: names, Elems: elems}
+}
+
+// MapValue makes a map whose keys are associated with the given elements.
+func MapValue(keys []Value, elems []Value) Value {
+	return Value{Kind: MapKind, Keys: keys, Elems: elems}
+}
+
+// NullValue makes the missing value.
+func NullValue() Value {
+	return Value{Kind: NullKind}
+}
+
+// Literals typed as numbers, which are the integers and the floating-point
+// numbers written as in JSON, so that they are rendered as valid literals in
+// every format. Other literals, such as 007, +5, .5 or inf, are strings.
+var (
+	intLiteral   = regexp.MustCompile(`^-?(0|[1-9][0-9]*)$`)
+	floatLiteral = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+)
+
+// literalValue types a literal from a descriptor, such as a value of an
+// enumeration, as a number when it is written as one and as a string
+// otherwise.
+func literalValue(text string) Value {
+	if intLiteral.MatchString(text) {
+		return Value{Kind: IntKind, Text: text}
+	}
+	if floatLiteral.MatchString(text) {
+		return Value{Kind: FloatKind, Text: text}
+	}
+	return StringValue(text)
+}
+
+// String formats a value as test inputs are formatted in the quoted format,
+// such as [1 2 3] for arrays, (1 true) for tuples and {name:"Alice" age:42}
+// for records, null values being formatted as null. Strings are written as
+// such, since each test input is a field of its own, except within
+// collections, where they are quoted as JSON strings so that their spaces,
+// colons and brackets cannot be mistaken for separators. Strings that could be
+// mistaken for null or for a quoted string are quoted too.
+func (v Value) String() string {
+	if v.Kind == StringKind && v.Text != "null" && !strings.HasPrefix(v.Text, `"`) {
+		return v.Text
+	}
+	var sb strings.Builder
+	v.write(&sb, true)
+	return sb.String()
+}
+
+// write formats a value, quoting the strings it contains or not.
+func (v Value) write(sb *strings.Builder, quoted bool) {
+	var open, close byte
+	switch v.Kind {
+	case ArrayKind:
+		open, close = '[', ']'
+	case TupleKind:
+		open, close = '(', ')'
+	case RecordKind, MapKind:
+		open, close = '{', '}'
+	case NullKind:
+		sb.WriteString("null")
+		return
+	case StringKind:
+		if quoted {
+			sb.WriteString(quote(v.Text))
+		} else {
+			sb.WriteString(v.Text)
+		}
+		return
+	default:
+		sb.WriteString(v.Text)
+		return
+	}
+
+	sb.WriteByte(open)
+	for i, elem := range v.Elems {
+		if i > 0 {
+			sb.WriteByte(' ')
+		}
+		switch v.Kind {
+		case RecordKind:
+			sb.WriteString(v.Names[i] + ":")
+		case MapKind:
+			v.Keys[i].write(sb, quoted)
+			sb.WriteByte(':')
+		}
+		elem.write(sb, quoted)
+	}
+	sb.WriteByte(close)
+}
+
+// quote quotes a string as a JSON string, without escaping HTML characters.
+func quote(s string) string {
+	var sb strings.Builder
+	encoder := json.NewEncoder(&sb)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// Number returns the value of a number.
+func (v Value) Number() (float64, bool) {
+	if v.Kind != IntKind && v.Kind != FloatKind && v.Kind != RawKind {
+		return 0, false
+	}
+	x, err := strconv.ParseFloat(v.Text, 64)
+	return x, err == nil
+}
+
+// Len returns the number of elements of an array, a tuple, a record or a map,
+// or the number of characters of any other value.
+func (v Value) Len() int {
+	switch v.Kind {
+	case ArrayKind, TupleKind, RecordKind, MapKind:
+		return len(v.Elems)
+	}
+	return utf8.RuneCountInString(v.Text)
+}
+
+// lessValues compares two generated values, numerically when both are numbers.
+func lessValues(a Value, b Value) bool {
+	x, okA := a.Number()
+	y, okB := b.Number()
+	if okA && okB {
+		return x < y
+	}
+	if a.Kind == StringKind && b.Kind == StringKind {
+		return a.Text < b.Text
+	}
+	return a.String() < b.String()
+}
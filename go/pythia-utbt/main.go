@@ -21,18 +21,27 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
 	"errors"
+	"flag"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"io/ioutil"
 	"log"
+	"math"
+	"math/rand"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"plugin"
 	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/pythia-project/libs/go/generators"
 )
@@ -50,9 +59,33 @@ type TestConfig struct {
 		Feedback map[string]string `json:"feedback,omitempty"`
 	} `json:"predefined,omitempty"`
 	Random struct {
-		N    int      `json:"n"`
-		Args []string `json:"args"`
+		N             int      `json:"n"`
+		Seed          *int64   `json:"seed,omitempty"`
+		PerSubmission bool     `json:"persubmission,omitempty"`
+		Args          []string `json:"args"`
+		Format        string   `json:"format,omitempty"`
+		Where         string   `json:"where,omitempty"`
+		MaxAttempts   int      `json:"maxattempts,omitempty"` // per row
+		Exhaustive    struct {
+			Enabled bool `json:"enabled"`
+			Cap     int  `json:"cap,omitempty"`
+		} `json:"exhaustive,omitempty"`
+		Coverage struct {
+			Enabled  bool `json:"enabled"`
+			Strength int  `json:"strength,omitempty"`
+			Buckets  int  `json:"buckets,omitempty"`
+		} `json:"coverage,omitempty"`
+		Shrink struct {
+			Enabled bool `json:"enabled"`
+			Budget  int  `json:"budget,omitempty"` // in milliseconds
+		} `json:"shrink,omitempty"`
+		Size struct {
+			Enabled bool    `json:"enabled"`
+			Curve   string  `json:"curve,omitempty"`
+			Min     float64 `json:"min,omitempty"`
+		} `json:"size,omitempty"`
 	} `json:"random,omitempty"`
+	Plugins []string `json:"plugins,omitempty"`
 }
 
 // Example contains a counterexample as a witness for a failed test.
@@ -80,27 +113,61 @@ type Feedback struct {
 type Grading struct {
 	Tid      string    `json:"tid"`
 	Status   string    `json:"status"`
+	Seed     *int64    `json:"seed,omitempty"`
 	Feedback *Feedback `json:"feedback,omitempty"`
 }
 
 const (
-	skeletonDir = "/task/skeleton"
+	taskDir     = "/task"
+	skeletonDir = taskDir + "/skeleton"
 
 	workDir    = "/tmp/work"
 	studentDir = workDir + "/student"
 	teacherDir = workDir + "/teacher"
+
+	testInputFile    = workDir + "/input/data.csv"
+	testValuesFile   = workDir + "/input/data.json"
+	testFormatFile   = workDir + "/input/format"
+	resultFile       = workDir + "/output/data.res"
+	solutionFile     = workDir + "/output/solution.res"
+	executeStateFile = workDir + "/execute"
+
+	// Default maximum number of test inputs enumerated in exhaustive mode.
+	defaultExhaustiveCap = 1000
+
+	// Default strength and number of buckets of numbers for the coverage of
+	// combinations of arguments.
+	defaultCoverageStrength = 2
+	defaultCoverageBuckets  = 3
+
+	// Default time budget for shrinking counterexamples, in milliseconds.
+	defaultShrinkBudget = 5000
+
+	// Default maximum number of attempts to generate a random row satisfying
+	// the where condition.
+	defaultMaxAttempts = 100
 )
 
+// Curves of the size of the random test inputs, mapping the progress in the
+// rows to the growth of the size, both comprised between 0 and 1.
+var sizeCurves = map[string]func(t float64) float64{
+	"":          func(t float64) float64 { return t },
+	"linear":    func(t float64) float64 { return t },
+	"quadratic": func(t float64) float64 { return t * t },
+	"sqrt":      math.Sqrt,
+}
+
 var fcts = map[string]func() error{
 	"preprocess": preprocess,
 	"generate":   generate,
 	"execute":    execute,
 	"feedback":   feedback,
+	"sample":     sample,
 }
 
 func main() {
 	if len(os.Args) < 2 {
-		log.Fatal("Subcommand is required (preprocess, generate, execute or feedback).")
+		log.Fatal("Subcommand is required (preprocess, generate, execute, feedback or sample).")
 	}
 
 	// Find the function to execute for given subcommand.
@@ -109,6 +176,9 @@ func main() {
 		log.Fatalf("Unknown subcommand: %s.", os.Args[1])
 	}
 
+	// Files named by descriptors, such as grammars, are shipped with the task.
+	generators.Dir = taskDir
+
 	// Execute the function associated to the subcommand.
 	if err := handler(); err != nil {
 		log.Fatalf("Error while executing %s: %s.", os.Args[1], err)
@@ -228,8 +298,6 @@ func saveTaskId(tid string) error {
 // Generate
 
 func generate() error {
-	var testInputFile = workDir + "/input/data.csv"
-
 	// Read and parse test configuration.
 	var config TestConfig
 	if err := readTestConfig("/task/config/test.json", &config); err != nil {
@@ -248,22 +316,188 @@ func generate() error {
 	writer.Comma = ';'
 	defer writer.Flush()
 
-	// Generate predefined test inputs.
+	// Save the format of the test inputs, so that runners parse them with it.
+	if err := ioutil.WriteFile(testFormatFile, []byte(config.Random.Format), 0444); err != nil {
+		return err
+	}
+
+	// Generate predefined test inputs, written in the format of the random ones
+	// when it is not the default one.
 	if config.Predefined != nil {
-		for _, data := range config.Predefined {
-			writer.Write(parseTestInputs(data.Data))
+		for i, data := range config.Predefined {
+			if config.Random.Format == "" {
+				writer.Write(parseTestInputs(data.Data))
+				continue
+			}
+			inputs := splitTestInputs(data.Data)
+			for _, input := range inputs {
+				if config.Random.Format == "json" && !json.Valid([]byte(input)) {
+					return fmt.Errorf("predefined test input %d is not valid JSON: %s", i+1, input)
+				}
+			}
+			writer.Write(inputs)
 		}
 	}
 
-	// Generate random test inputs.
-	if config.Random.N > 0 {
-		generators := generators.BuildGenerators(config.Random.Args...)
-		for i := 0; i < config.Random.N; i++ {
-			writer.Write(generateTestInputs(generators))
+	if config.Random.N <= 0 && !config.Random.Exhaustive.Enabled && !config.Random.Coverage.Enabled {
+		return nil
+	}
+
+	if err := loadPlugins(&config); err != nil {
+		return err
+	}
+	gens, err := generators.BuildGenerators(config.Random.Args...)
+	if err != nil {
+		return err
+	}
+	order, err := generators.EvaluationOrder(gens)
+	if err != nil {
+		return err
+	}
+	render, err := generators.FindRenderer(config.Random.Format)
+	if err != nil {
+		return err
+	}
+	where, err := whereCondition(&config)
+	if err != nil {
+		return err
+	}
+
+	// Enumerate all the test inputs when there are not too many of them.
+	if config.Random.Exhaustive.Enabled {
+		limit := config.Random.Exhaustive.Cap
+		if limit <= 0 {
+			limit = defaultExhaustiveCap
+		}
+		if rows, ok := generators.EnumerateInputs(gens, order, limit); ok {
+			if rows, err = filterTestInputs(where, rows); err != nil {
+				return err
+			}
+			return writeTestInputs(writer, render, rows)
 		}
 	}
 
-	return nil
+	seed, err := randomSeed(&config)
+	if err != nil {
+		return err
+	}
+	if err := saveSeed(seed); err != nil {
+		return err
+	}
+	r := rand.New(rand.NewSource(seed))
+
+	// Generate random test inputs, whose size grows with the rows if required.
+	sizes, err := testSizes(&config)
+	if err != nil {
+		return err
+	}
+	maxAttempts := config.Random.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+	var rows [][]generators.Value
+	attempts := 0
+	for i := 0; i < config.Random.N; i++ {
+		inputs, err := generateAcceptedInputs(r, gens, order, sizes[i], where, maxAttempts, i, &attempts)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, inputs)
+	}
+
+	// Generate test inputs covering the combinations of arguments.
+	if config.Random.Coverage.Enabled {
+		strength := config.Random.Coverage.Strength
+		if strength <= 0 {
+			strength = defaultCoverageStrength
+		}
+		buckets := config.Random.Coverage.Buckets
+		if buckets <= 0 {
+			buckets = defaultCoverageBuckets
+		}
+		covering, err := generators.CoverInputs(r, gens, order, strength, buckets)
+		if err != nil {
+			return err
+		}
+		if covering, err = filterTestInputs(where, covering); err != nil {
+			return err
+		}
+		rows = append(rows, covering...)
+	}
+
+	return writeTestInputs(writer, render, rows)
+}
+
+// whereCondition parses the condition that the random test inputs must
+// satisfy, if any.
+func whereCondition(config *TestConfig) (*generators.Condition, error) {
+	if config.Random.Where == "" {
+		return nil, nil
+	}
+	where, err := generators.ParseCondition(config.Random.Where)
+	if err != nil {
+		return nil, fmt.Errorf("invalid where condition: %s", err)
+	}
+	return where, nil
+}
+
+// acceptTestInputs checks whether test inputs satisfy the where condition.
+func acceptTestInputs(where *generators.Condition, row []generators.Value) (bool, error) {
+	if where == nil {
+		return true, nil
+	}
+	ok, err := where.Holds(row)
+	if err != nil {
+		return false, fmt.Errorf("where condition: %s", err)
+	}
+	return ok, nil
+}
+
+// filterTestInputs keeps the test inputs satisfying the where condition.
+func filterTestInputs(where *generators.Condition, rows [][]generators.Value) ([][]generators.Value, error) {
+	var accepted [][]generators.Value
+	for _, row := range rows {
+		ok, err := acceptTestInputs(where, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			accepted = append(accepted, row)
+		}
+	}
+	return accepted, nil
+}
+
+// writeTestInputs writes generated test inputs rendered in the format of the
+// configuration, and saves their typed values so that they can be shrunk.
+func writeTestInputs(writer *csv.Writer, render generators.Renderer, rows [][]generators.Value) error {
+	for _, row := range rows {
+		writer.Write(renderTestInputs(render, row))
+	}
+
+	content, err := json.Marshal(rows)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(testValuesFile, content, 0444)
+}
+
+func renderTestInputs(render generators.Renderer, row []generators.Value) []string {
+	inputs := make([]string, len(row))
+	for i, value := range row {
+		inputs[i] = render(value)
+	}
+	return inputs
+}
+
+func loadTestValues() ([][]generators.Value, error) {
+	content, err := ioutil.ReadFile(testValuesFile)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]generators.Value
+	err = json.Unmarshal(content, &rows)
+	return rows, err
 }
 
 func readTestConfig(path string, config *TestConfig) error {
@@ -284,13 +518,192 @@ func parseTestInputs(str string) []string {
 	return inputs
 }
 
-func generateTestInputs(gens []generators.RandomGenerator) []string {
-	inputs := make([]string, len(gens))
-	for i, g := range gens {
-		inputs[i] = g.Generate()
+// splitTestInputs splits test inputs written as literals of a language, such
+// as ([1, 2], "a, b"), on the commas that are neither nested in brackets nor
+// in quoted strings.
+func splitTestInputs(str string) []string {
+	str = strings.TrimSpace(str)
+	str = str[1 : len(str)-1]
+
+	var inputs []string
+	depth, start := 0, 0
+	var quote byte
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case quote != 0:
+			if c == '\\' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '(' || c == '[' || c == '{':
+			depth++
+		case c == ')' || c == ']' || c == '}':
+			depth--
+		case c == ',' && depth == 0:
+			inputs = append(inputs, strings.TrimSpace(str[start:i]))
+			start = i + 1
+		}
+	}
+	return append(inputs, strings.TrimSpace(str[start:]))
+}
+
+// loadPlugins opens the Go plugins listed in the configuration, whose paths
+// are relative to the task directory, so that they can register their own
+// generators from their init functions.
+func loadPlugins(config *TestConfig) error {
+	for _, path := range config.Plugins {
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(taskDir, path)
+		}
+		if _, err := plugin.Open(path); err != nil {
+			return fmt.Errorf("cannot load plugin %s: %s", path, err)
+		}
 	}
+	return nil
+}
 
-	return inputs
+// testSizes computes the size of each random test input, from the minimum size
+// to the full size following the configured curve, or the full size for all
+// of them when progressive sizes are disabled.
+func testSizes(config *TestConfig) ([]float64, error) {
+	n := config.Random.N
+	sizes := make([]float64, n)
+	if !config.Random.Size.Enabled {
+		for i := range sizes {
+			sizes[i] = 1
+		}
+		return sizes, nil
+	}
+
+	curve, ok := sizeCurves[config.Random.Size.Curve]
+	if !ok {
+		return nil, fmt.Errorf("unknown size curve '%s'", config.Random.Size.Curve)
+	}
+	min := config.Random.Size.Min
+	if min < 0 || min > 1 {
+		return nil, errors.New("minimum size must be comprised between 0 and 1")
+	}
+	for i := range sizes {
+		t := 1.0
+		if n > 1 {
+			t = float64(i) / float64(n-1)
+		}
+		sizes[i] = min + (1-min)*curve(t)
+	}
+	return sizes, nil
+}
+
+// generateTestInputs generates the inputs of one test, in the given order so
+// that dependent generators can be resolved with the arguments they refer to,
+// with generators scaled down to the given size.
+func generateTestInputs(r *rand.Rand, gens []generators.Argument, order []int, size float64) ([]generators.Value, error) {
+	inputs := make([]generators.Value, len(gens))
+	for _, i := range order {
+		g, err := gens[i].Resolve(inputs)
+		if err != nil {
+			return nil, fmt.Errorf("argument $%d: %s", i+1, err)
+		}
+		inputs[i] = generators.GenerateValue(generators.Resize(g, size), r)
+	}
+
+	return inputs, nil
+}
+
+// generateAcceptedInputs generates random test inputs until they satisfy the
+// where condition, counting the attempts. Rows for which a dependent generator
+// cannot be resolved, such as int(0,len($1)-1) with an empty $1, are rejected
+// as well. It gives up after the maximum number of attempts, reporting the
+// acceptance rate given the number of rows accepted so far. The size is
+// relaxed with the failed attempts, since the smallest values may never
+// satisfy the condition.
+func generateAcceptedInputs(r *rand.Rand, gens []generators.Argument, order []int, size float64, where *generators.Condition, maxAttempts int, accepted int, attempts *int) ([]generators.Value, error) {
+	var unresolved error
+	for attempt := 0; attempt < maxAttempts; attempt++ {
+		*attempts++
+		inputs, err := generateTestInputs(r, gens, order, size+(1-size)*float64(attempt)/float64(maxAttempts))
+		if err != nil {
+			unresolved = err
+			continue
+		}
+		ok, err := acceptTestInputs(where, inputs)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			return inputs, nil
+		}
+	}
+
+	msg := fmt.Sprintf("only %d of %d generated rows were accepted (%.1f%%), giving up after %d attempts for a row", accepted, *attempts, 100*float64(accepted)/float64(*attempts), maxAttempts)
+	if where != nil {
+		msg = fmt.Sprintf("where condition '%s': %s", where, msg)
+	}
+	if unresolved != nil {
+		msg += fmt.Sprintf(", last error: %s", unresolved)
+	}
+	return nil, errors.New(msg)
+}
+
+// randomSeed computes the seed of the random test inputs. The configured seed
+// is used when there is one, and is combined with a digest of the submission
+// when per-submission derivation is enabled. Otherwise, a fresh seed is drawn.
+func randomSeed(config *TestConfig) (int64, error) {
+	var seed int64
+	if config.Random.Seed != nil {
+		seed = *config.Random.Seed
+	} else if !config.Random.PerSubmission {
+		return time.Now().UnixNano(), nil
+	}
+
+	if config.Random.PerSubmission {
+		digest, err := hashSubmission()
+		if err != nil {
+			return 0, err
+		}
+		seed ^= digest
+	}
+	return seed, nil
+}
+
+// hashSubmission computes a digest of the task id and of the files filled
+// with the inputs of the learner.
+func hashSubmission() (int64, error) {
+	hash := fnv.New64a()
+
+	content, err := ioutil.ReadFile(workDir + "/tid")
+	if err != nil {
+		return 0, err
+	}
+	hash.Write(content)
+
+	err = filepath.Walk(studentDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.Mode().IsDir() {
+			content, err := ioutil.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			hash.Write([]byte(path))
+			hash.Write(content)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int64(hash.Sum64()), nil
+}
+
+func saveSeed(seed int64) error {
+	return ioutil.WriteFile(workDir+"/seed", []byte(strconv.FormatInt(seed, 10)), 0444)
 }
 
 ////////////////////////////////////////////////////////////////////////////////
@@ -301,6 +714,12 @@ func execute() error {
 		return errors.New("Command to execute is missing.")
 	}
 
+	// Save the command, so that the code from the learner can be executed again
+	// on shrunk counterexamples.
+	if err := saveExecuteCommand(os.Args[2:]); err != nil {
+		return err
+	}
+
 	// Execute the code from the learner.
 	if err := executeCommand(os.Args[2], os.Args[3:]...); err != nil {
 		return err
@@ -309,6 +728,24 @@ func execute() error {
 	return nil
 }
 
+func saveExecuteCommand(command []string) error {
+	content, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(executeStateFile, content, 0444)
+}
+
+func loadExecuteCommand() ([]string, error) {
+	content, err := ioutil.ReadFile(executeStateFile)
+	if err != nil {
+		return nil, err
+	}
+	var command []string
+	err = json.Unmarshal(content, &command)
+	return command, err
+}
+
 func executeCommand(command string, args ...string) error {
 	cmd := exec.Command(command, args...)
 	return cmd.Run()
@@ -326,6 +763,11 @@ func feedback() error {
 		return err
 	}
 
+	// Load the seed of the random test inputs, if any were generated.
+	if seed, ok := loadSeed(); ok {
+		grading.Seed = &seed
+	}
+
 	// Read and parse test configuration.
 	var config TestConfig
 	if err := readTestConfig("/task/config/test.json", &config); err != nil {
@@ -359,21 +801,22 @@ func feedback() error {
 	stats.Total = 0
 	grading.Status = "success"
 
-	results, err := readLines(workDir + "/output/data.res")
+	results, err := readLines(resultFile)
 	if err != nil {
 		return err
 	}
-	solutions, err := readLines(workDir + "/output/solution.res")
+	solutions, err := readLines(solutionFile)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.Open(workDir + "/input/data.csv")
+	file, err := os.Open(testInputFile)
 	if err != nil {
 		return err
 	}
 	reader := csv.NewReader(file)
 	reader.Comma = ';'
+	failed := -1
 	i := -1
 	for {
 		i++
@@ -395,6 +838,9 @@ func feedback() error {
 
 			if feedback.Example == nil {
 				grading.Status = "failed"
+				if i >= len(config.Predefined) {
+					failed = i - len(config.Predefined)
+				}
 				feedback.Example = &Example{
 					Input:    "(" + strings.Join(row, ",") + ")",
 					Expected: solutions[i],
@@ -417,6 +863,16 @@ func feedback() error {
 		}
 	}
 	stats.Total = i
+	file.Close()
+
+	// Replace a random counterexample with a shrunk one, if enabled.
+	if failed >= 0 && config.Random.Shrink.Enabled {
+		example, err := shrinkExample(&config, failed, feedback.Example)
+		if err != nil {
+			return err
+		}
+		feedback.Example = example
+	}
 
 	// Generate feedback result
 	feedback.Stats = &stats
@@ -431,6 +887,186 @@ func feedback() error {
 	return nil
 }
 
+// shrinkExample searches for a smaller failing test input, QuickCheck style,
+// within the time budget of the configuration. Candidates produced by the
+// generators are executed with the code of the learner and of the author, in
+// batches, and the first one still failing replaces the current input.
+func shrinkExample(config *TestConfig, index int, example *Example) (*Example, error) {
+	budget := config.Random.Shrink.Budget
+	if budget <= 0 {
+		budget = defaultShrinkBudget
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(budget)*time.Millisecond)
+	defer cancel()
+
+	if err := loadPlugins(config); err != nil {
+		return nil, err
+	}
+	gens, err := generators.BuildGenerators(config.Random.Args...)
+	if err != nil {
+		return nil, err
+	}
+	render, err := generators.FindRenderer(config.Random.Format)
+	if err != nil {
+		return nil, err
+	}
+	where, err := whereCondition(config)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := loadTestValues()
+	if err != nil {
+		return nil, err
+	}
+	if index >= len(rows) || len(rows[index]) != len(gens) {
+		return example, nil
+	}
+	row := rows[index]
+	command, err := loadExecuteCommand()
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore the test inputs and results once done.
+	restore, err := backupFiles(testInputFile, resultFile, solutionFile)
+	if err != nil {
+		return nil, err
+	}
+	defer restore()
+
+	for ctx.Err() == nil {
+		// Candidates must remain valid test inputs.
+		candidates, err := filterTestInputs(where, shrinkCandidates(gens, row))
+		if err != nil {
+			return nil, err
+		}
+		found := false
+		for start := 0; start < len(candidates) && !found && ctx.Err() == nil; start += shrinkBatchSize {
+			end := start + shrinkBatchSize
+			if end > len(candidates) {
+				end = len(candidates)
+			}
+			results, solutions, err := runCandidates(ctx, command, render, candidates[start:end])
+			if err != nil {
+				// Candidates interrupted by the time budget are discarded.
+				break
+			}
+			for i, result := range results {
+				tokens := strings.SplitN(result, ":", 2)
+				if len(tokens) == 2 && tokens[0] == "checked" && i < len(solutions) && tokens[1] != solutions[i] {
+					row = candidates[start+i]
+					example = &Example{
+						Input:    "(" + strings.Join(renderTestInputs(render, row), ",") + ")",
+						Expected: solutions[i],
+						Actual:   tokens[1],
+					}
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	return example, nil
+}
+
+// Number of candidates executed at once while shrinking a counterexample.
+const shrinkBatchSize = 50
+
+// shrinkCandidates proposes test inputs where one argument is shrunk. Arguments
+// referred to by dependent generators are left as is, and dependent generators
+// are resolved with the other arguments of the test input.
+func shrinkCandidates(gens []generators.Argument, row []generators.Value) [][]generators.Value {
+	referred := make(map[int]bool)
+	for _, g := range gens {
+		for _, j := range g.Dependencies() {
+			referred[j] = true
+		}
+	}
+
+	var candidates [][]generators.Value
+	for i, arg := range gens {
+		if referred[i] {
+			continue
+		}
+		g, err := arg.Resolve(row)
+		if err != nil {
+			continue
+		}
+		for _, value := range generators.Shrink(g, row[i]) {
+			candidate := append([]generators.Value{}, row...)
+			candidate[i] = value
+			candidates = append(candidates, candidate)
+		}
+	}
+	return candidates
+}
+
+// runCandidates executes the code of the learner and of the author on the
+// given test inputs, and returns their results.
+func runCandidates(ctx context.Context, command []string, render generators.Renderer, rows [][]generators.Value) ([]string, []string, error) {
+	file, err := os.Create(testInputFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	writer := csv.NewWriter(file)
+	writer.Comma = ';'
+	for _, row := range rows {
+		writer.Write(renderTestInputs(render, row))
+	}
+	writer.Flush()
+	file.Close()
+	if err := writer.Error(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := exec.CommandContext(ctx, command[0], command[1:]...).Run(); err != nil {
+		return nil, nil, err
+	}
+	if err := exec.CommandContext(ctx, os.Args[2], os.Args[3:]...).Run(); err != nil {
+		return nil, nil, err
+	}
+
+	results, err := readLines(resultFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	solutions, err := readLines(solutionFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	return results, solutions, nil
+}
+
+// backupFiles saves the content and permissions of files, and returns a
+// function restoring them.
+func backupFiles(paths ...string) (func(), error) {
+	contents := make([][]byte, len(paths))
+	modes := make([]os.FileMode, len(paths))
+	for i, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if contents[i], err = ioutil.ReadFile(path); err != nil {
+			return nil, err
+		}
+		modes[i] = info.Mode()
+		if err := os.Chmod(path, 0666); err != nil {
+			return nil, err
+		}
+	}
+
+	return func() {
+		for i, path := range paths {
+			ioutil.WriteFile(path, contents[i], modes[i])
+			os.Chmod(path, modes[i])
+		}
+	}, nil
+}
+
 func loadTaskId(tid *string) error {
 	content, err := ioutil.ReadFile(workDir + "/tid")
 	if err != nil {
@@ -440,6 +1076,18 @@ func loadTaskId(tid *string) error {
 	return nil
 }
 
+func loadSeed() (int64, bool) {
+	content, err := ioutil.ReadFile(workDir + "/seed")
+	if err != nil {
+		return 0, false
+	}
+	seed, err := strconv.ParseInt(string(content), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return seed, true
+}
+
 func executeSolution() error {
 	// Read author solution.
 	var solution map[string]string
@@ -490,3 +1138,222 @@ func printGrading(grading Grading) error {
 	fmt.Println(string(result))
 	return nil
 }
+
+////////////////////////////////////////////////////////////////////////////////
+// Sample
+
+const (
+	// Default number of samples generated from the descriptors.
+	defaultSamples = 10
+
+	// Maximum number of distinct values whose frequencies are summarised.
+	maxFrequencies = 10
+
+	// Maximum number of bars of the histograms, and width of the longest one.
+	maxHistogramBars = 10
+	histogramWidth   = 40
+)
+
+// sample prints random values generated from descriptors, given as arguments
+// or else taken from the test configuration, followed by a summary of their
+// distributions, so that descriptors can be checked before deploying a task.
+func sample() error {
+	flags := flag.NewFlagSet("sample", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: %s sample [options] [descriptor...]\n", os.Args[0])
+		flags.PrintDefaults()
+	}
+	n := flags.Int("n", defaultSamples, "number of samples, at least 1")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the random generator")
+	dir := flags.String("dir", taskDir, "task directory, against which the paths of files are resolved")
+	format := flags.String("format", "", "format of the samples (quoted, python, java, c or json)")
+	where := flags.String("where", "", "condition that the samples must satisfy")
+	flags.Parse(os.Args[2:])
+	if *n < 1 {
+		fmt.Fprintf(flags.Output(), "invalid value %d for flag -n: number of samples must be positive\n", *n)
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	// Take the descriptors from the test configuration if none is given, the
+	// format and condition given on the command line taking precedence.
+	var config TestConfig
+	config.Random.Args = flags.Args()
+	if flags.NArg() == 0 {
+		if err := readTestConfig(filepath.Join(*dir, "config/test.json"), &config); err != nil {
+			return err
+		}
+		for i, path := range config.Plugins {
+			if !filepath.IsAbs(path) {
+				config.Plugins[i] = filepath.Join(*dir, path)
+			}
+		}
+		if err := loadPlugins(&config); err != nil {
+			return err
+		}
+	}
+	if *format != "" {
+		config.Random.Format = *format
+	}
+	if *where != "" {
+		config.Random.Where = *where
+	}
+	generators.Dir = *dir
+
+	gens, err := generators.BuildGenerators(config.Random.Args...)
+	if err != nil {
+		return err
+	}
+	order, err := generators.EvaluationOrder(gens)
+	if err != nil {
+		return err
+	}
+	render, err := generators.FindRenderer(config.Random.Format)
+	if err != nil {
+		return err
+	}
+	condition, err := whereCondition(&config)
+	if err != nil {
+		return err
+	}
+	maxAttempts := config.Random.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = defaultMaxAttempts
+	}
+
+	r := rand.New(rand.NewSource(*seed))
+	var rows [][]generators.Value
+	attempts := 0
+	for i := 0; i < *n; i++ {
+		inputs, err := generateAcceptedInputs(r, gens, order, 1, condition, maxAttempts, i, &attempts)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, inputs)
+	}
+
+	fmt.Printf("Samples (seed %d):\n", *seed)
+	for _, row := range rows {
+		fmt.Printf("  %s\n", strings.Join(renderTestInputs(render, row), ";"))
+	}
+	if condition != nil && attempts > 0 {
+		fmt.Printf("\nWhere condition accepted %d of %d generated rows (%.1f%%)\n", len(rows), attempts, 100*float64(len(rows))/float64(attempts))
+	}
+	for i, desc := range config.Random.Args {
+		values := make([]generators.Value, len(rows))
+		for j, row := range rows {
+			values[j] = row[i]
+		}
+		fmt.Printf("\n$%d %s\n", i+1, desc)
+		summarise(os.Stdout, values)
+	}
+	return nil
+}
+
+// summarise prints the range of the numbers and of the lengths of values, the
+// frequencies of values when there are few distinct ones, and the histogram of
+// the lengths when they vary.
+func summarise(w io.Writer, values []generators.Value) {
+	var numbers, lengths []float64
+	nulls := 0
+	counts := make(map[string]int)
+	var distinct []string
+	for _, value := range values {
+		if x, ok := value.Number(); ok {
+			numbers = append(numbers, x)
+		}
+		switch value.Kind {
+		case generators.StringKind, generators.ArrayKind, generators.TupleKind, generators.RecordKind, generators.MapKind:
+			lengths = append(lengths, float64(value.Len()))
+		case generators.NullKind:
+			nulls++
+		}
+		text := value.String()
+		if counts[text] == 0 {
+			distinct = append(distinct, text)
+		}
+		counts[text]++
+	}
+
+	if len(numbers) > 0 {
+		fmt.Fprintf(w, "  numbers   %s\n", describeRange(numbers))
+	}
+	if len(lengths) > 0 {
+		fmt.Fprintf(w, "  lengths   %s\n", describeRange(lengths))
+	}
+	if nulls > 0 {
+		fmt.Fprintf(w, "  nulls     %s\n", describeCount(nulls, len(values)))
+	}
+	if len(distinct) <= maxFrequencies {
+		sort.SliceStable(distinct, func(i, j int) bool {
+			return counts[distinct[i]] > counts[distinct[j]]
+		})
+		pad := 9
+		for _, text := range distinct {
+			if len(text) > pad {
+				pad = len(text)
+			}
+		}
+		for _, text := range distinct {
+			fmt.Fprintf(w, "  %-*s %s\n", pad, text, describeCount(counts[text], len(values)))
+		}
+	}
+	if len(lengths) > 0 {
+		printHistogram(w, lengths)
+	}
+}
+
+func describeRange(values []float64) string {
+	min, max, total := values[0], values[0], 0.0
+	for _, x := range values {
+		min, max, total = math.Min(min, x), math.Max(max, x), total+x
+	}
+	return fmt.Sprintf("min %s, max %s, mean %s", formatFloat(min), formatFloat(max), formatFloat(total/float64(len(values))))
+}
+
+func describeCount(count int, total int) string {
+	return fmt.Sprintf("%d (%.1f%%)", count, 100*float64(count)/float64(total))
+}
+
+func formatFloat(x float64) string {
+	return strconv.FormatFloat(math.Round(x*1000)/1000, 'f', -1, 64)
+}
+
+// printHistogram prints the histogram of integers, such as lengths, grouping
+// them into buckets of equal widths when they span too many values.
+func printHistogram(w io.Writer, values []float64) {
+	min, max := int(values[0]), int(values[0])
+	for _, x := range values {
+		if int(x) < min {
+			min = int(x)
+		}
+		if int(x) > max {
+			max = int(x)
+		}
+	}
+	if min == max {
+		return
+	}
+	width := (max - min + maxHistogramBars) / maxHistogramBars
+
+	counts := make([]int, (max-min)/width+1)
+	highest := 0
+	for _, x := range values {
+		i := (int(x) - min) / width
+		counts[i]++
+		if counts[i] > highest {
+			highest = counts[i]
+		}
+	}
+	for i, count := range counts {
+		label := strconv.Itoa(min + i*width)
+		if last := min + (i+1)*width - 1; width > 1 {
+			if last > max {
+				last = max
+			}
+			label += "-" + strconv.Itoa(last)
+		}
+		bar := strings.Repeat("#", (count*histogramWidth+highest-1)/highest)
+		fmt.Fprintf(w, "  %9s | %-*s %d\n", label, histogramWidth, bar, count)
+	}
+}
@@ -0,0 +1,169 @@
+// Pythia utilities for unit testing-based tasks
+// Author: Sébastien Combéfis <sebastien@combefis.be>
+//
+// Copyright (C) 2019, Computer Science and IT in Education ASBL
+// Copyright (C) 2019, ECAM Brussels Engineering School
+//
+// This program is free software: you can redistribute it and/or modify
+// under the terms of the GNU General Public License as published by
+// the Free Software Foundation, version 2 of the License, or
+//  (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the GNU
+// General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <http://www.gnu.org/licenses/>.
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/pythia-project/libs/go/generators"
+)
+
+// generateRows generates rows of test inputs from descriptors with a seed.
+func generateRows(t *testing.T, seed int64, n int, descs ...string) [][]generators.Value {
+	t.Helper()
+	gens, err := generators.BuildGenerators(descs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	order, err := generators.EvaluationOrder(gens)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := rand.New(rand.NewSource(seed))
+	rows := make([][]generators.Value, n)
+	for i := range rows {
+		if rows[i], err = generateTestInputs(r, gens, order, 1); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return rows
+}
+
+func TestSeededInputs(t *testing.T) {
+	descs := []string{"int(0,1000)", "array(0,10)[float(-1,1)]", "str(1,8)"}
+	first, second := generateRows(t, 42, 20, descs...), generateRows(t, 42, 20, descs...)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("rows generated with the same seed differ:\n%v\n%v", first, second)
+	}
+	if other := generateRows(t, 43, 20, descs...); reflect.DeepEqual(first, other) {
+		t.Errorf("rows generated with different seeds are the same")
+	}
+}
+
+func TestRandomSeed(t *testing.T) {
+	var config TestConfig
+	seed := int64(1234)
+	config.Random.Seed = &seed
+	if got, err := randomSeed(&config); err != nil || got != seed {
+		t.Errorf("got seed %d (%v), expected the configured seed %d", got, err, seed)
+	}
+}
+
+func TestSizes(t *testing.T) {
+	tests := []struct {
+		enabled  bool
+		curve    string
+		min      float64
+		n        int
+		expected []float64
+	}{
+		{false, "", 0, 3, []float64{1, 1, 1}},
+		{true, "", 0, 5, []float64{0, 0.25, 0.5, 0.75, 1}},
+		{true, "linear", 0.5, 3, []float64{0.5, 0.75, 1}},
+		{true, "quadratic", 0, 3, []float64{0, 0.25, 1}},
+		{true, "sqrt", 0, 2, []float64{0, 1}},
+		{true, "quadratic", 0.2, 1, []float64{1}},
+	}
+	for _, test := range tests {
+		var config TestConfig
+		config.Random.N = test.n
+		config.Random.Size.Enabled = test.enabled
+		config.Random.Size.Curve = test.curve
+		config.Random.Size.Min = test.min
+		sizes, err := testSizes(&config)
+		if err != nil {
+			t.Errorf("%+v: %s", config.Random.Size, err)
+		} else if !reflect.DeepEqual(sizes, test.expected) {
+			t.Errorf("%+v: got sizes %v, expected %v", config.Random.Size, sizes, test.expected)
+		}
+	}
+
+	invalid := map[string]float64{"cubic": 0, "linear": 1.5, "sqrt": -0.1}
+	for curve, min := range invalid {
+		var config TestConfig
+		config.Random.N = 2
+		config.Random.Size.Enabled = true
+		config.Random.Size.Curve = curve
+		config.Random.Size.Min = min
+		if _, err := testSizes(&config); err == nil {
+			t.Errorf("%+v: expected an error", config.Random.Size)
+		}
+	}
+}
+
+func TestDescriptions(t *testing.T) {
+	if got := describeRange([]float64{1, 2, 4}); got != "min 1, max 4, mean 2.333" {
+		t.Errorf("got range %q", got)
+	}
+	if got := describeRange([]float64{-0.5}); got != "min -0.5, max -0.5, mean -0.5" {
+		t.Errorf("got range %q", got)
+	}
+	if got := describeCount(1, 3); got != "1 (33.3%)" {
+		t.Errorf("got count %q", got)
+	}
+	floats := map[float64]string{0: "0", 1e6: "1000000", 0.1234: "0.123", -2.0005: "-2.001"}
+	for x, expected := range floats {
+		if got := formatFloat(x); got != expected {
+			t.Errorf("formatFloat(%g) = %q, expected %q", x, got, expected)
+		}
+	}
+}
+
+func TestSummarise(t *testing.T) {
+	var b bytes.Buffer
+	summarise(&b, []generators.Value{
+		generators.IntValue(3), generators.IntValue(1), generators.NullValue(), generators.IntValue(3),
+	})
+	expected := "  numbers   min 1, max 3, mean 2.333\n" +
+		"  nulls     1 (25.0%)\n" +
+		"  3         2 (50.0%)\n" +
+		"  1         1 (25.0%)\n" +
+		"  null      1 (25.0%)\n"
+	if b.String() != expected {
+		t.Errorf("got summary\n%s\nexpected\n%s", b.String(), expected)
+	}
+
+	// Lengths are summarised with a histogram, and frequencies are left out
+	// when there are too many distinct values.
+	b.Reset()
+	var values []generators.Value
+	for i := 0; i <= maxFrequencies; i++ {
+		values = append(values, generators.StringValue(strings.Repeat("a", i%4+1)+string('a'+rune(i))))
+	}
+	summarise(&b, values)
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 5 || lines[0] != "  lengths   min 2, max 5, mean 3.364" {
+		t.Fatalf("unexpected summary\n%s", b.String())
+	}
+	if bar := strings.Repeat("#", histogramWidth); lines[1] != "          2 | "+bar+" 3" {
+		t.Errorf("unexpected histogram bar %q", lines[1])
+	}
+
+	b.Reset()
+	printHistogram(&b, []float64{0, 5, 25, 25})
+	expected = "        0-2 | " + strings.Repeat("#", 20) + strings.Repeat(" ", 20) + " 1\n"
+	if lines := strings.Split(b.String(), "\n"); len(lines) != 10 || lines[0]+"\n" != expected {
+		t.Errorf("unexpected histogram\n%s", b.String())
+	}
+}
@@ -28,8 +28,14 @@ import java.io.IOException;
 import java.lang.reflect.InvocationTargetException;
 import java.lang.reflect.Method;
 
+import java.math.BigDecimal;
+import java.math.BigInteger;
+
+import java.util.ArrayList;
 import java.util.Arrays;
+import java.util.Collection;
 import java.util.List;
+import java.util.Map;
 
 import org.json.JSONArray;
 import org.json.JSONObject;
@@ -50,6 +56,85 @@ public class Execute
 		return buff.toString();
 	}
 
+	/**
+	 * Finds the method to execute, with the name and the number of parameters
+	 * of the specification. Overloaded methods are told apart with the types
+	 * of the specification, and the method is not found when several of them
+	 * match these types.
+	 */
+	private static Method findMethod (Class<?> program, String name, JSONArray params) throws NoSuchMethodException
+	{
+		List<Method> candidates = new ArrayList<>();
+		for (Method method : program.getDeclaredMethods())
+		{
+			if (method.getName().equals (name) && method.getParameterCount() == params.length())
+			{
+				candidates.add (method);
+			}
+		}
+		if (candidates.size() == 1)
+		{
+			return candidates.get (0);
+		}
+
+		List<Method> matching = new ArrayList<>();
+		for (Method method : candidates)
+		{
+			Class<?>[] types = method.getParameterTypes();
+			boolean matches = true;
+			for (int i = 0; i < types.length && matches; i++)
+			{
+				matches = matchesType (types[i], params.getJSONObject (i).getString ("type"));
+			}
+			if (matches)
+			{
+				matching.add (method);
+			}
+		}
+		if (matching.size() == 1)
+		{
+			return matching.get (0);
+		}
+		if (matching.isEmpty())
+		{
+			throw new NoSuchMethodException (String.format ("no method %s with %d parameters of the types of the specification", name, params.length()));
+		}
+		throw new NoSuchMethodException (String.format ("ambiguous overloads of method %s for the types of the specification", name));
+	}
+
+	/**
+	 * Checks whether a parameter of the given class can receive the values of
+	 * the given type of the specification.
+	 */
+	private static boolean matchesType (Class<?> type, String specType)
+	{
+		if (type == Object.class)
+		{
+			return true;
+		}
+		if (specType.startsWith ("[]") || specType.startsWith ("("))
+		{
+			return type.isArray() || Collection.class.isAssignableFrom (type);
+		}
+		if (specType.startsWith ("{") || specType.startsWith ("map["))
+		{
+			return Map.class.isAssignableFrom (type);
+		}
+		switch (specType)
+		{
+			case "int":
+				return Arrays.asList (int.class, Integer.class, long.class, Long.class, short.class, Short.class, byte.class, Byte.class, BigInteger.class).contains (type);
+			case "float":
+				return Arrays.asList (double.class, Double.class, float.class, Float.class, BigDecimal.class).contains (type);
+			case "bool":
+				return type == boolean.class || type == Boolean.class;
+			case "str":
+			case "enum":
+				return type == String.class || type == char.class || type == Character.class;
+		}
+		return false;
+	}
+
 	public static void main (String[] args) throws ClassNotFoundException, IOException, NoSuchMethodException
 	{
 		if (args.length < 1 || ! Arrays.asList("student", "teacher").contains(args[0]))
@@ -64,16 +149,7 @@ public class Execute
 		Class<?> program = ClassLoader.getSystemClassLoader().loadClass ("Program");
 		String name = spec.getString ("name");
 		JSONArray params = spec.getJSONArray ("args");
-		Class<?>[] paramsType = new Class<?>[params.length()];
-		for (int i = 0; i < params.length(); i++)
-		{
-			switch (params.getJSONObject (i).getString ("type"))
-			{
-				case "int":
-					paramsType[i] = int.class;
-			}
-		}
-		Method method = program.getDeclaredMethod (name, paramsType);
+		Method method = findMethod (program, name, params);
 
 		// Create the specific runner for the code to execute.
 		String inputFile = "/tmp/work/input/data.csv";
@@ -114,6 +190,8 @@ public class Execute
 			outputFile = "solution.res";
 		}
 
+		// Values read from JSON are converted to the declared parameter types.
+		runner.setParameterTypes (method.getGenericParameterTypes());
 		runner.run ("/tmp/work/output", outputFile);
 	}
 }
@@ -21,18 +21,38 @@ package org.pythia;
 
 import java.io.BufferedReader;
 import java.io.BufferedWriter;
+import java.io.File;
 import java.io.FileReader;
 import java.io.FileWriter;
 import java.io.IOException;
 import java.io.Reader;
 import java.io.Writer;
 
+import java.lang.reflect.Array;
+import java.lang.reflect.GenericArrayType;
+import java.lang.reflect.ParameterizedType;
+import java.lang.reflect.Type;
+
+import java.math.BigDecimal;
+import java.math.BigInteger;
+
+import java.nio.file.Files;
+
+import java.util.ArrayList;
+import java.util.Collection;
+import java.util.LinkedHashMap;
+import java.util.LinkedHashSet;
+import java.util.List;
+import java.util.Map;
+import java.util.Set;
+
 import org.apache.commons.csv.CSVFormat;
 import org.apache.commons.csv.CSVParser;
 import org.apache.commons.csv.CSVRecord;
 
 import org.json.JSONArray;
 import org.json.JSONObject;
+import org.json.JSONTokener;
 
 /**
  * Basic code runner.
@@ -41,6 +61,8 @@ public abstract class Runner
 {
 	private String inputFile;
 	private JSONObject spec;
+	private Type[] parameterTypes;
+	private String format;
 
 	protected Runner (String inputFile, JSONObject spec)
 	{
@@ -48,6 +70,15 @@ public abstract class Runner
 		this.spec = spec;
 	}
 
+	/**
+	 * Sets the declared types of the parameters of the method under test, to
+	 * which the values read from JSON are converted.
+	 */
+	public void setParameterTypes (Type[] parameterTypes)
+	{
+		this.parameterTypes = parameterTypes;
+	}
+
 	protected String check (Object[] data)
 	{
 		return String.format ("%s", code (data));
@@ -58,14 +89,200 @@ public abstract class Runner
 	private Object[] parseTestData (CSVRecord data)
 	{
 		JSONArray params = spec.getJSONArray ("args");
+		boolean json = "json".equals (format);
 		Object[] args = new Object[params.length()];
 		for (int i = 0; i < params.length(); i++)
 		{
-			args[i] = parse (data.get (i), params.getJSONObject (i).getString ("type"));
+			if (json)
+			{
+				Object value = parseJSON (data.get (i));
+				args[i] = parameterTypes != null && i < parameterTypes.length ? convert (value, parameterTypes[i]) : value;
+			}
+			else
+			{
+				args[i] = parse (data.get (i), params.getJSONObject (i).getString ("type"));
+			}
 		}
 		return args;
 	}
 
+	private Object parseJSON (String data)
+	{
+		Object value = new JSONTokener (data).nextValue();
+		if (value instanceof JSONArray)
+		{
+			return ((JSONArray) value).toList();
+		}
+		if (value instanceof JSONObject)
+		{
+			return ((JSONObject) value).toMap();
+		}
+		if (JSONObject.NULL.equals (value))
+		{
+			return null;
+		}
+		return value;
+	}
+
+	/**
+	 * Converts a value read from JSON, made of lists, maps, strings, numbers
+	 * and booleans, to the given type. Lists are converted to arrays, lists and
+	 * sets, and maps, given as objects or as lists of [key, value] pairs, to
+	 * maps whose keys are converted too.
+	 */
+	private static Object convert (Object value, Type type)
+	{
+		if (value == null)
+		{
+			return null;
+		}
+
+		Class<?> raw = rawClass (type);
+		if (raw == null)
+		{
+			return value;
+		}
+		if (raw.isArray() && value instanceof List)
+		{
+			Type component = type instanceof GenericArrayType ? ((GenericArrayType) type).getGenericComponentType() : raw.getComponentType();
+			List<?> list = (List<?>) value;
+			Object array = Array.newInstance (raw.getComponentType(), list.size());
+			for (int i = 0; i < list.size(); i++)
+			{
+				Array.set (array, i, convert (list.get (i), component));
+			}
+			return array;
+		}
+		if (Collection.class.isAssignableFrom (raw) && value instanceof List)
+		{
+			Type elem = typeArgument (type, 0);
+			Collection<Object> collection = Set.class.isAssignableFrom (raw) ? new LinkedHashSet<Object>() : new ArrayList<Object>();
+			for (Object x : (List<?>) value)
+			{
+				collection.add (convert (x, elem));
+			}
+			return collection;
+		}
+		if (Map.class.isAssignableFrom (raw))
+		{
+			Type keyType = typeArgument (type, 0);
+			Type valueType = typeArgument (type, 1);
+			Map<Object, Object> map = new LinkedHashMap<>();
+			if (value instanceof Map)
+			{
+				for (Map.Entry<?, ?> entry : ((Map<?, ?>) value).entrySet())
+				{
+					map.put (convert (entry.getKey(), keyType), convert (entry.getValue(), valueType));
+				}
+				return map;
+			}
+			if (value instanceof List)
+			{
+				for (Object pair : (List<?>) value)
+				{
+					List<?> entry = (List<?>) pair;
+					map.put (convert (entry.get (0), keyType), convert (entry.get (1), valueType));
+				}
+				return map;
+			}
+		}
+		return convertScalar (value, raw);
+	}
+
+	private static Object convertScalar (Object value, Class<?> type)
+	{
+		if (type == String.class)
+		{
+			return value.toString();
+		}
+		if ((type == char.class || type == Character.class) && value instanceof String && ((String) value).length() == 1)
+		{
+			return ((String) value).charAt (0);
+		}
+		if (type == boolean.class || type == Boolean.class)
+		{
+			return value instanceof Boolean ? value : Boolean.valueOf (value.toString());
+		}
+
+		// Numbers may also come from the keys of maps, which are strings.
+		BigDecimal number = null;
+		if (value instanceof Number || value instanceof String)
+		{
+			try
+			{
+				number = new BigDecimal (value.toString());
+			}
+			catch (NumberFormatException exception)
+			{
+				return value;
+			}
+		}
+		if (number == null)
+		{
+			return value;
+		}
+		if (type == int.class || type == Integer.class)
+		{
+			return number.intValue();
+		}
+		if (type == long.class || type == Long.class)
+		{
+			return number.longValue();
+		}
+		if (type == short.class || type == Short.class)
+		{
+			return number.shortValue();
+		}
+		if (type == byte.class || type == Byte.class)
+		{
+			return number.byteValue();
+		}
+		if (type == double.class || type == Double.class)
+		{
+			return number.doubleValue();
+		}
+		if (type == float.class || type == Float.class)
+		{
+			return number.floatValue();
+		}
+		if (type == BigInteger.class)
+		{
+			return number.toBigInteger();
+		}
+		if (type == BigDecimal.class)
+		{
+			return number;
+		}
+		return value;
+	}
+
+	private static Class<?> rawClass (Type type)
+	{
+		if (type instanceof Class)
+		{
+			return (Class<?>) type;
+		}
+		if (type instanceof ParameterizedType)
+		{
+			return rawClass (((ParameterizedType) type).getRawType());
+		}
+		if (type instanceof GenericArrayType)
+		{
+			Class<?> component = rawClass (((GenericArrayType) type).getGenericComponentType());
+			return component == null ? null : Array.newInstance (component, 0).getClass();
+		}
+		return null;
+	}
+
+	private static Type typeArgument (Type type, int i)
+	{
+		if (type instanceof ParameterizedType)
+		{
+			return ((ParameterizedType) type).getActualTypeArguments()[i];
+		}
+		return Object.class;
+	}
+
 	private Object parse (String data, String type)
 	{
 		switch (type)
@@ -76,8 +293,33 @@ public abstract class Runner
 		return null;
 	}
 
+	/**
+	 * Reads the format of the test inputs, saved next to them by pythia-utbt,
+	 * or else takes the one of the specification.
+	 */
+	private String readFormat () throws IOException
+	{
+		File file = new File (new File (inputFile).getParentFile(), "format");
+		if (! file.exists())
+		{
+			return spec.optString ("format");
+		}
+
+		String format = new String (Files.readAllBytes (file.toPath()), "UTF-8").trim();
+		if (spec.has ("format") && ! format.equals (spec.getString ("format")))
+		{
+			throw new IOException (String.format ("format '%s' of the specification differs from format '%s' of the test inputs", spec.getString ("format"), format));
+		}
+		return format;
+	}
+
 	public void run (String dest, String filename) throws IOException
 	{
+		format = readFormat();
+		if (! format.isEmpty() && ! "quoted".equals (format) && ! "json".equals (format))
+		{
+			throw new IOException (String.format ("unsupported format '%s' of the test inputs, expected json, quoted or the default one", format));
+		}
 		try
 		(
 			// Create the results file
@@ -20,13 +20,79 @@
 # along with this program.  If not, see <http://www.gnu.org/licenses/>.
 
 from abc import ABC, abstractmethod
+import ast
 import csv
+import json
+import os
+
+def _unquoted(data, start=0, quotes=True):
+    '''Enumerate the characters of data that are not in quoted strings, when
+    strings are quoted.'''
+    quoted, escaped = False, False
+    for i in range(start, len(data)):
+        c = data[i]
+        if not quotes:
+            yield i, c
+        elif quoted:
+            if escaped:
+                escaped = False
+            elif c == '\\':
+                escaped = True
+            elif c == '"':
+                quoted = False
+        elif c == '"':
+            quoted = True
+        else:
+            yield i, c
+
+def _split(data, sep, quotes=False):
+    '''Split data on separators that are not nested in brackets or, when
+    strings are quoted, in quoted strings.'''
+    parts, depth, start = [], 0, 0
+    for i, c in _unquoted(data, quotes=quotes):
+        if c in '([{':
+            depth += 1
+        elif c in ')]}':
+            depth -= 1
+        elif c == sep and depth == 0:
+            parts.append(data[start:i].strip())
+            start = i + 1
+    if data[start:].strip() != '':
+        parts.append(data[start:].strip())
+    return parts
+
+def _closing(data, start):
+    '''Find the index of the bracket closing the one at the start index.'''
+    depth = 0
+    for i, c in _unquoted(data, start):
+        if c in '([{':
+            depth += 1
+        elif c in ')]}':
+            depth -= 1
+            if depth == 0:
+                return i
+    return -1
 
 class Runner(ABC):
     '''Basic code runner.'''
     def __init__(self, inputfile, spec):
         self.inputfile = inputfile
         self.spec = spec
+        self.format = self._format()
+        if self.format not in ('', 'quoted', 'python', 'json'):
+            raise ValueError("unsupported format '{}' of the test inputs, expected python, json, quoted or the default one".format(self.format))
+
+    def _format(self):
+        '''Read the format of the test inputs, saved next to them by pythia-utbt,
+        or else take the one of the specification.'''
+        path = os.path.join(os.path.dirname(self.inputfile), 'format')
+        if not os.path.exists(path):
+            return self.spec.get('format', '')
+        with open(path, 'r', encoding='utf-8') as file:
+            format = file.read().strip()
+        if self.spec.get('format', format) != format:
+            raise ValueError("format '{}' of the specification differs from format '{}' of the test inputs".format(self.spec['format'], format))
+        return format
 
     def check(self, data):
         return '{}'.format(self.code(data))
@@ -36,9 +102,37 @@ class Runner(ABC):
         pass
 
     def parseTestData(self, data):
+        if self.format == 'python':
+            return tuple(ast.literal_eval(x) for x in data)
+        if self.format == 'json':
+            return tuple(self._convert(json.loads(data[i]), self.spec['args'][i]['type']) for i in range(len(data)))
         return tuple(self._parse(data[i], self.spec['args'][i]['type']) for i in range(len(data)))
 
+    def _convert(self, value, type):
+        '''Convert a value read from JSON to the given type.'''
+        if value is None:
+            return None
+        if type[:2] == '[]':
+            return [self._convert(x, type[2:]) for x in value]
+        if type[:1] == '(':
+            types = _split(type[1:len(type)-1], ',')
+            return tuple(self._convert(value[i], types[i]) for i in range(len(types)))
+        if type[:1] == '{':
+            types = dict(_split(x, ':') for x in _split(type[1:len(type)-1], ','))
+            return {name: self._convert(x, types[name]) for (name, x) in value.items()}
+        if type[:4] == 'map[':
+            end = _closing(type, 3)
+            keytype, valuetype = type[4:end], type[end+1:]
+            entries = value.items() if isinstance(value, dict) else value
+            return {self._convert(key, keytype): self._convert(x, valuetype) for (key, x) in entries}
+        return value
+
     def _parse(self, data, type):
+        if data == 'null':
+            return None
+        quotes = self.format == 'quoted'
+        if quotes and data[:1] == '"':
+            return json.loads(data)
         if type == 'int':
             return int(data)
         if type == 'bool':
@@ -48,10 +142,20 @@ class Runner(ABC):
         if type in ['enum', 'str']:
             return data
         if type[:2] == '[]':
-            if type[2:] == 'int':
-                if data == '[]':
-                    return []
-                return [int(x) for x in data[1:len(data)-1].split(' ')]
+            return [self._parse(x, type[2:]) for x in _split(data[1:len(data)-1], ' ', quotes)]
+        if type[:1] == '(':
+            types = _split(type[1:len(type)-1], ',')
+            values = _split(data[1:len(data)-1], ' ', quotes)
+            return tuple(self._parse(values[i], types[i]) for i in range(len(types)))
+        if type[:1] == '{':
+            types = dict(_split(x, ':') for x in _split(type[1:len(type)-1], ','))
+            values = (_split(x, ':', quotes) for x in _split(data[1:len(data)-1], ' ', quotes))
+            return {name: self._parse(value, types[name]) for (name, value) in values}
+        if type[:4] == 'map[':
+            end = _closing(type, 3)
+            keytype, valuetype = type[4:end], type[end+1:]
+            values = (_split(x, ':', quotes) for x in _split(data[1:len(data)-1], ' ', quotes))
+            return {self._parse(key, keytype): self._parse(value, valuetype) for (key, value) in values}
         return None
 
     def run(self, dest, filename):